@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/installations"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of downloaded release assets",
+	Long: `
+Fox keeps the release assets it downloads in ` + constants.FoxDownloadsCachePath + `,
+so reinstalling, switching versions or installing the same package under another
+name doesn't download them again. The executables of 'fox run' are kept there too.
+
+The cache is limited by 'downloadCacheSizeMB' in your config (see 'fox config'),
+the least recently used downloads are removed first.
+
+	List the cached downloads:
+	$ fox cache list
+
+	Remove the downloads over the size limit, and anything broken:
+	$ fox cache prune
+
+	Remove every cached download:
+	$ fox cache clear
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the cached downloads",
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, err := installations.LoadDownloadCache()
+		checkErr(err, cmd)
+
+		fmt.Println()
+		if len(cache.Downloads) == 0 {
+			color.Blue(" The downloads cache is empty ¯\\_(ツ)_/¯")
+			fmt.Println()
+			return
+		}
+
+		sort.Slice(cache.Downloads, func(i, j int) bool {
+			return cache.Downloads[i].LastUsed > cache.Downloads[j].LastUsed
+		})
+
+		for _, d := range cache.Downloads {
+			if d.RunKey != "" {
+				color.Green("  • %s, for 'fox run'", d.Name)
+			} else {
+				color.Green("  • %s", d.Name)
+			}
+			fmt.Printf("      %s@%s, %s, last used %s\n", d.Repo, d.Tag, utils.ByteCountIEC(d.Size), time.UnixMilli(d.LastUsed).Format(time.RFC822))
+		}
+
+		limit := installations.DownloadCacheLimit(userConfig)
+		fmt.Println()
+		color.Blue(" Using %s of %s", utils.ByteCountIEC(installations.DownloadCacheSize(cache)), utils.ByteCountIEC(limit))
+		fmt.Println()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the cached downloads over the size limit, and anything broken",
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := installations.PruneDownloadCache(userConfig)
+		checkErr(err, cmd)
+
+		for _, d := range removed {
+			color.Yellow(" Removed: %s (%s@%s)", d.Name, d.Repo, d.Tag)
+		}
+
+		color.Green(" 🧹 Pruned the downloads cache, removed %d download(s)", len(removed))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached download",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := installations.ClearDownloadCache()
+		checkErr(err, cmd)
+
+		color.Green(" 🧹 Cleared the downloads cache")
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
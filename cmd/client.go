@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// newClient returns the client the commands run through, with the config and repositories of the user
+func newClient(interactive bool) *fox.Client {
+	return newClientWithOutput(interactive, nil)
+}
+
+// newClientWithOutput is newClient sending the messages of fox to output instead of stdout
+func newClientWithOutput(interactive bool, output io.Writer) *fox.Client {
+	client, err := fox.New(fox.Options{
+		Remotes: lo.Map(repositoriesConfig.Remotes, func(r repositoriesTypes.Remote, _ int) fox.Remote {
+			return fox.Remote(r)
+		}),
+		Packages:    lo.Map(repositoriesConfig.Packages, toClientPackage),
+		Config:      toClientConfig(userConfig),
+		Output:      output,
+		Interactive: interactive,
+	})
+	checkErr(err, nil)
+
+	return client
+}
+
+func toClientPackage(p repositoriesTypes.ConfigPackage, _ int) fox.ConfigPackage {
+	return fox.ConfigPackage{
+		Path:           p.Path,
+		ExecutableName: p.ExecutableName,
+		Type:           p.Type,
+		DependsOn:      p.DependsOn,
+		Executables: lo.Map(p.Executables, func(e repositoriesTypes.Executable, _ int) fox.Executable {
+			return fox.Executable(e)
+		}),
+		CompletionsCommand: p.CompletionsCommand,
+		Assets:             p.Assets,
+		BinaryPath:         p.BinaryPath,
+		Test:               p.Test,
+		Hooks:              fox.Hooks(p.Hooks),
+		Env:                p.Env,
+		Args:               p.Args,
+	}
+}
+
+func toClientConfig(c types.UserConfig) fox.Config {
+	return fox.Config{
+		AutoUpdate:             c.AutoUpdate,
+		NotifyOutdatedVersions: c.NotifyOutdatedVersions,
+		InstallManPages:        c.InstallManPages,
+		InstallCompletions:     c.InstallCompletions,
+		DownloadCacheSizeMB:    c.DownloadCacheSizeMB,
+		HTTPTimeoutSeconds:     c.HTTPTimeoutSeconds,
+		Network:                fox.NetworkConfig(c.NetworkConfig),
+		Hosts: lo.MapValues(c.Hosts, func(n types.NetworkConfig, _ string) fox.NetworkConfig {
+			return fox.NetworkConfig(n)
+		}),
+	}
+}
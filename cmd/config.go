@@ -27,16 +27,46 @@ The following are the available values and what they mean:
   • notifyOutdatedVersions (bool) [default: true]:
        You can control if fox notifies you about if a new version is available
        for your installed packages before 'install' and 'info''
+  • installManPages (bool) [default: true]:
+       Install the man pages shipped in release archives into ` + constants.FoxManPath + `
+  • installCompletions (bool) [default: true]:
+       Install the shell completions shipped in release archives, or generated by the package,
+       into ` + constants.FoxSharePath + `. Run 'fox shellenv' to see how to load them.
+  • downloadCacheSizeMB (int) [default: 1024]:
+       How big the cache of downloaded release assets in ` + constants.FoxDownloadsCachePath + ` can get.
+       The least recently used downloads are removed first. A negative value disables the cache.
+  • httpTimeoutSeconds (int) [default: 30]:
+       How long fox waits for a server to respond, or for a download to receive more data,
+       before retrying. Downloads are retried with backoff and resume where they were left.
+  • proxy (string) [default: HTTPS_PROXY and NO_PROXY]:
+       The URL of the proxy every request goes through, including the ones gh makes.
+  • caBundle (string):
+       A PEM file with certificates to trust on top of the system ones, eg: an internal root CA.
+  • clientCert and clientKey (string):
+       PEM files to authenticate to servers that ask for a client certificate.
+       The key can be in the clientCert file.
+  • hosts (map):
+       Overrides of proxy, caBundle, clientCert and clientKey for some hosts.
+       A leading dot matches the subdomains too, and a proxy of "direct" skips the proxy:
+         hosts:
+           artifacts.corp.example:
+             proxy: direct
+             clientCert: ~/.certs/fox.pem
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		if isMachineOutput() {
+			checkErr(printOutput(userConfig), cmd)
+			return
+		}
+
 		out, err := yaml.Marshal(&userConfig)
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		fmt.Println()
 		color.Blue("        This is your configuration, sir.")
 		color.Blue("        Located at '~" + constants.ConfigFilePath + "'.")
 		color.Blue(" o(_ _)o\n\n")
 		err = utils.PrintYAML(string(out))
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		fmt.Println()
 	},
 }
@@ -50,34 +80,44 @@ func initConfig() {
 	// create config file if it doesn't exist
 	if !utils.FileExistsInHome(constants.ConfigFilePath) {
 		err := writeDefaultConfig()
-		utils.CheckErr(err, nil)
+		checkErr(err, nil)
 	}
 
 	// Find home directory.
 	home, err := os.UserHomeDir()
-	utils.CheckErr(err, nil)
+	checkErr(err, nil)
 	viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("config")
+	// configs written before these settings existed don't have them
+	viper.SetDefault("installManPages", true)
+	viper.SetDefault("installCompletions", true)
 
 	err = viper.ReadInConfig()
-	utils.CheckErr(err, nil)
+	checkErr(err, nil)
 
 	err = viper.Unmarshal(&userConfig)
-	utils.CheckErr(err, nil)
+	checkErr(err, nil)
 	viper.Reset() // reset viper since we use it for different config files
+
+	err = utils.ConfigureNetwork(userConfig)
+	checkErr(err, nil)
 	// fmt.Printf("%v", userConfig)
 }
 
 // writeDefaultConfig Creates a default configuration file
 func writeDefaultConfig() error {
-	utils.CreateDirectoryIfNotExistsInHome(constants.ConfigDirectoryPath)
-	err := utils.CreateFileIfNotExistsInHome(constants.ConfigFilePath)
-	utils.CheckErr(err, nil)
+	err := utils.CreateDirectoryIfNotExistsInHome(constants.ConfigDirectoryPath)
+	if err != nil {
+		return err
+	}
+
+	err = utils.CreateFileIfNotExistsInHome(constants.ConfigFilePath)
+	checkErr(err, nil)
 
 	// Find home directory.
 	home, err := os.UserHomeDir()
-	utils.CheckErr(err, nil)
+	checkErr(err, nil)
 	viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("config")
@@ -85,6 +125,10 @@ func writeDefaultConfig() error {
 	// Global config
 	viper.Set("autoUpdate", true)
 	viper.Set("notifyOutdatedVersions", true)
+	viper.Set("installManPages", true)
+	viper.Set("installCompletions", true)
+	viper.Set("downloadCacheSizeMB", constants.DefaultDownloadCacheSizeMB)
+	viper.Set("httpTimeoutSeconds", constants.DefaultHTTPTimeoutSeconds)
 
 	err = viper.WriteConfig()
 	if err != nil {
@@ -10,9 +10,11 @@ import (
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/installations"
 	"github.com/ricardofabila/fox/src/utils"
 )
 
@@ -24,6 +26,7 @@ var doctorCmd = &cobra.Command{
 	// • Your configuration file.
 	Long: `This commands checks:
 • You have dependencies installed.
+• Your OS, architecture, C library and CPU, which fox uses to pick what to download.
 • That you have the right permissions for the folder fox uses.`,
 	Run: giveItToMeStraightDoctorICanTakeIt,
 }
@@ -44,6 +47,11 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 
 	warnings := 0
 	danger := 0
+	// what was checked, for the json and yaml output
+	var checks []doctorCheckOutput
+	check := func(section, name, status, message string) {
+		checks = append(checks, doctorCheckOutput{Section: section, Name: name, Status: status, Message: message})
+	}
 
 	// -------------------------------------------- CONFIG --------------------------------------------
 	color.Green("    🔍 Looking at your config file located at ~" + constants.ConfigFilePath + ":\n")
@@ -52,10 +60,12 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 		color.White("                ✅ You have 'notifyOutdatedVersions' set to true")
 		color.White("                This means fox will tell you when there is a new version")
 		color.White("                for packages that you have installed.")
+		check("config", "notifyOutdatedVersions", "ok", "fox will tell you when there is a new version for packages that you have installed")
 	} else {
 		color.Yellow("                💉 You have 'notifyOutdatedVersions' set to false")
 		color.Yellow("                This means fox will not tell you when there is a new version")
 		color.Yellow("                for packages that you have installed.")
+		check("config", "notifyOutdatedVersions", "warning", "fox will not tell you when there is a new version for packages that you have installed")
 		warnings++
 	}
 
@@ -64,11 +74,13 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 		color.White("                This means fox will update its cache automatically")
 		color.White("                when running various command, so you always install")
 		color.White("                the latest and greatest version available for a package.")
+		check("config", "autoUpdate", "ok", "fox updates its cache automatically")
 	} else {
 		color.Yellow("                💉 You have 'AutoUpdate' set to false")
 		color.Yellow("                This means fox will NOT update its cache automatically")
 		color.Yellow("                when running various command, so may install an outdated")
 		color.Yellow("                version of a package.")
+		check("config", "autoUpdate", "warning", "fox does not update its cache automatically, you may install an outdated version of a package")
 		warnings++
 	}
 
@@ -82,9 +94,11 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 		color.Red("            ❌ You don't have `gh` installed or is not in your $PATH.")
 		color.Red("               You won't be able to make any API calls and won't be able to download anything.")
 		color.Red("               %s", err)
+		check("dependencies", "gh", "problem", "gh is not installed or is not in your $PATH: "+err.Error())
 		danger++
 	} else {
 		color.White("                ✅ gh is installed.")
+		check("dependencies", "gh", "ok", "gh is installed")
 	}
 
 	if err == nil {
@@ -95,7 +109,9 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 		data, err := utils.ExecuteCommandAndGetOutput("gh", []string{"auth", "status"}...)
 		if err != nil {
 			_ = utils.PrintAndReturnError(err.Error())
+			warnings++
 		}
+		check("dependencies", "ghAuth", lo.Ternary(err == nil, "ok", "warning"), strings.TrimSpace(data))
 
 		lines := strings.Split(data, "\n")
 		for _, line := range lines {
@@ -110,6 +126,32 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 	color.Green("    🔍 Looking at your OS and ARCHITECTURE:\n")
 	color.White("                ✅ Your OS is: " + runtime.GOOS)
 	color.White("                ✅ Your ARCHITECTURE is: " + runtime.GOARCH)
+	check("platform", "os", "ok", runtime.GOOS)
+	check("platform", "architecture", "ok", runtime.GOARCH)
+
+	// what fox uses to pick the right asset of a release
+	host := installations.CurrentHost()
+	if host.OS == "linux" {
+		if host.Libc == "" {
+			color.Yellow("                💉 Could not tell if your system uses glibc or musl.")
+			color.Yellow("                fox may download builds that don't run on your system.")
+			check("platform", "libc", "warning", "could not tell if your system uses glibc or musl")
+			warnings++
+		} else {
+			color.White("                ✅ Your C library is: " + lo.Ternary(host.Libc == "gnu", "glibc", host.Libc))
+			check("platform", "libc", "ok", lo.Ternary(host.Libc == "gnu", "glibc", host.Libc))
+		}
+	}
+
+	if host.AMD64Level > 0 {
+		color.White("                ✅ Your CPU supports: x86-64-v%d", host.AMD64Level)
+		check("platform", "cpu", "ok", fmt.Sprintf("x86-64-v%d", host.AMD64Level))
+	}
+
+	if host.ARMVariant > 0 {
+		color.White("                ✅ Your CPU is: armv%d", host.ARMVariant)
+		check("platform", "cpu", "ok", fmt.Sprintf("armv%d", host.ARMVariant))
+	}
 	fmt.Println()
 
 	// -------------------------------------------- PERMISSIONS --------------------------------------------
@@ -147,9 +189,16 @@ func giveItToMeStraightDoctorICanTakeIt(*cobra.Command, []string) {
 		color.Red("            ❌ You don't have the right permissions for: " + constants.FoxRootPath)
 		color.Red("               You probably won't be able download nor run anything. Or will need to use `sudo` constantly")
 		color.Cyan("              Run: sudo chmod -R 777 " + constants.FoxRootPath)
+		check("permissions", constants.FoxRootPath, "problem", "wrong permissions, run: sudo chmod -R 777 "+constants.FoxRootPath)
 		danger++
 	} else {
 		color.White("                ✅ Correct file permissions for: " + constants.FoxRootPath)
+		check("permissions", constants.FoxRootPath, "ok", "correct file permissions")
+	}
+
+	if isMachineOutput() {
+		checkErr(printOutput(doctorOutput{Checks: checks, Warnings: warnings, Problems: danger}), nil)
+		return
 	}
 
 	if warnings+danger == 0 {
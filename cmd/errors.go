@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// The exit codes of fox, documented in 'fox --help'
+const (
+	ExitError             = 1
+	ExitUpgradesAvailable = 2
+	ExitNetwork           = 3
+	ExitAuth              = 4
+	ExitNotFound          = 5
+	ExitStateCorruption   = 6
+	ExitAborted           = 130
+)
+
+// exitCode returns the exit code for the class of the error
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, utils.ErrAborted):
+		return ExitAborted
+	case errors.Is(err, utils.ErrAuth):
+		return ExitAuth
+	case errors.Is(err, utils.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, utils.ErrNetwork):
+		return ExitNetwork
+	case errors.Is(err, utils.ErrStateCorruption):
+		return ExitStateCorruption
+	}
+
+	return ExitError
+}
+
+// errorClass names the class of the error in the json and yaml output
+func errorClass(err error) string {
+	switch exitCode(err) {
+	case ExitAborted:
+		return "aborted"
+	case ExitAuth:
+		return "auth"
+	case ExitNotFound:
+		return "notFound"
+	case ExitNetwork:
+		return "network"
+	case ExitStateCorruption:
+		return "stateCorruption"
+	}
+
+	return "error"
+}
+
+// checkErr prints the error and exits with the exit code of its class. If the error is nil, it does nothing.
+func checkErr(e error, cmd *cobra.Command) {
+	if e == nil {
+		return
+	}
+
+	if isMachineOutput() {
+		_ = printOutput(errorOutput{Error: errorDetails{Message: e.Error(), Class: errorClass(e), ExitCode: exitCode(e)}})
+		os.Exit(exitCode(e))
+	}
+
+	if errors.Is(e, utils.ErrAborted) {
+		color.Green(" (Ͼ˳Ͽ)..!!! " + e.Error())
+		os.Exit(ExitAborted)
+	}
+
+	// color.Output is stderr when stdout belongs to something else, eg: 'fox run'
+	fmt.Fprintln(color.Output)
+	fmt.Fprintln(color.Output)
+	_, err := color.New(color.FgRed).Fprintln(os.Stderr, " (╯°□°)╯︵ ɹoɹɹƎ \n\n\n "+e.Error())
+	if err != nil {
+		color.Red(err.Error())
+	}
+
+	fmt.Fprintln(color.Output)
+	fmt.Fprintln(color.Output)
+
+	if cmd != nil {
+		_ = cmd.Help()
+	}
+
+	os.Exit(exitCode(e))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/sys/execabs"
 
+	"github.com/ricardofabila/fox/src/constants"
 	"github.com/ricardofabila/fox/src/installations"
 	"github.com/ricardofabila/fox/src/types"
 	"github.com/ricardofabila/fox/src/types/repositories"
@@ -47,32 +48,32 @@ For private repos, make sure you have auth set https://cli.github.com/manual/gh_
 
 		color.Yellow("\n Looks like you don't have gh installed or is not in your $PATH.\n\n")
 		latestGhRelease, err := utils.GetFromAPI("https://api.github.com/repos/cli/cli/releases/latest")
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		if !utils.IsValidJSON(string(latestGhRelease)) {
-			utils.CheckErr(fmt.Errorf("Error, the response by GitHub was not valid JSON: \n"+string(latestGhRelease)), cmd)
+			checkErr(fmt.Errorf("Error, the response by GitHub was not valid JSON: \n"+string(latestGhRelease)), cmd)
 		}
 
 		release := repositories.Release{}
 		err = json.Unmarshal(latestGhRelease, &release)
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 
 		pkg := repositories.Package{
 			ExecutableName: "gh",
 			Type:           "binary",
 		}
 
-		assetToDownload, err := installations.GetAssetToDownloadForBinary(pkg, release.Assets, false)
-		utils.CheckErr(err, cmd)
+		assetToDownload, err := installations.GetAssetToDownloadForBinary(pkg, release, false, false)
+		checkErr(err, cmd)
 		color.Magenta(" Fetching the asset " + assetToDownload.Name + " of size " + utils.ByteCountIEC(int64(assetToDownload.Size)))
 
-		err = utils.DownloadFile(assetToDownload.BrowserDownloadURL, assetToDownload.Name)
-		utils.CheckErr(err, cmd)
+		err = utils.DownloadFile(assetToDownload.BrowserDownloadURL, assetToDownload.Name, int64(assetToDownload.Size))
+		checkErr(err, cmd)
 
 		_, err = installations.ExtractAsset(assetToDownload.Name, pkg.ExecutableName)
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 
 		err = installations.MoveAssetToBin("gh", "gh")
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 
 		color.Green("\n\n 🦊 Installed gh, now go install more cool packages!")
 		color.Green(" You can see a hand-picked list of public packages running:")
@@ -87,7 +88,11 @@ For private repos, make sure you have auth set https://cli.github.com/manual/gh_
 			Version:        release.Tag,
 		}
 
-		installations.SaveInstallation(install)
+		install.SHA256, install.Size, err = utils.FileDigest(constants.FoxBinPath + "gh")
+		checkErr(err, cmd)
+
+		err = installations.SaveInstallation(install)
+		checkErr(err, cmd)
 	},
 }
 
@@ -1,25 +1,46 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/rivo/tview"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
-	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/repositories"
-	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
-	"github.com/ricardofabila/fox/src/utils"
+	"github.com/ricardofabila/fox/pkg/fox"
 )
 
+type InfoFlags struct {
+	releases bool
+	limit    int
+}
+
+var infoFlags = InfoFlags{
+	releases: false,
+	limit:    0,
+}
+
 // infoCmd represents the info command
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Get info about a specific package",
 	Long:  `Get info about a specific package`,
+	Example: `
+	Get info about a package:
+	$ fox info <package_name>
+
+	Browse its releases, with the assets for your system and the release notes, to pick a version:
+	$ fox info <package_name> --releases
+
+	Only the 3 latest releases:
+	$ fox info <package_name> --releases --limit 3
+`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			_ = cmd.Help()
@@ -33,38 +54,72 @@ var infoCmd = &cobra.Command{
 		}
 
 		desiredRepo := args[0]
+		client := newClient(true)
 
-		packages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-		utils.CheckErr(err, cmd)
-
-		if userConfig.NotifyOutdatedVersions {
-			installs := installations.LoadInstallations()
-			installations.NotifyNewVersions(packages, installs)
+		if userConfig.NotifyOutdatedVersions && !isMachineOutput() {
+			outdated, err := client.Outdated(context.Background())
+			checkErr(err, cmd)
+			notifyOutdated(outdated)
 		}
 
-		var found *repositoriesTypes.Package
-		for _, repo := range packages {
-			if strings.EqualFold(desiredRepo, repo.ExecutableName) {
-				found = &repo
-				break
-			}
-		}
-
-		if found == nil {
+		info, err := client.Info(context.Background(), desiredRepo)
+		if errors.Is(err, fox.ErrNotFound) && !isMachineOutput() {
 			color.Yellow("       Sorry, I couldn't find a package with the name: " + desiredRepo)
 			color.Yellow("       Try running 'fox update' first.")
 			color.Yellow(" ٩(๏̯๏)۶")
 			fmt.Println()
-			os.Exit(1)
+			os.Exit(ExitNotFound)
+		}
+		checkErr(err, cmd)
+
+		var releases []fox.Release
+		if infoFlags.releases {
+			releases, err = client.Releases(context.Background(), info.Package.ExecutableName)
+			checkErr(err, cmd)
+			if infoFlags.limit > 0 && len(releases) > infoFlags.limit {
+				releases = releases[:infoFlags.limit]
+			}
+		}
+
+		if isMachineOutput() {
+			checkErr(printOutput(packageInfoOutput{
+				Package:       toPackageOutput(info.Package, releases),
+				Installations: toInstallationsOutput(info.Installations),
+			}), cmd)
+			return
 		}
 
 		detailsText := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
-		detailsText.SetText(renderDetails(*found, func(s string) {}))
+		detailsText.SetText(renderDetails(info.Package, func(s string) {}))
 		fmt.Println(detailsText.GetText(true))
 		fmt.Println()
+
+		for _, i := range info.Installations {
+			if !i.IsWrapped() {
+				continue
+			}
+
+			color.Blue("	🎁 %s@%s runs through a wrapper:", i.RealName, i.Version)
+			fmt.Println("	   Executable: " + i.StorePath)
+			names := lo.Keys(i.Env)
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Println("	   Env: " + name + "=" + i.Env[name])
+			}
+			if len(i.Args) > 0 {
+				fmt.Println("	   Args: " + strings.Join(i.Args, " "))
+			}
+			fmt.Println()
+		}
+
+		if infoFlags.releases {
+			printReleases(info.Package, releases)
+		}
 	},
 }
 
 func init() {
+	infoCmd.Flags().BoolVarP(&infoFlags.releases, "releases", "r", false, "List the releases of the package with their assets and release notes")
+	infoCmd.Flags().IntVarP(&infoFlags.limit, "limit", "n", 0, "With --releases, list at most this many releases, 0 lists them all")
 	rootCmd.AddCommand(infoCmd)
 }
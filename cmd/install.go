@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -8,8 +9,8 @@ import (
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/ricardofabila/fox/pkg/fox"
 	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/repositories"
 	"github.com/ricardofabila/fox/src/utils"
 )
 
@@ -17,12 +18,20 @@ type InstallFlags struct {
 	alias       string
 	force       bool
 	interactive bool
+	explain     bool
+	noHooks     bool
+	env         []string
+	args        []string
 }
 
 var installFlags = InstallFlags{
 	alias:       "",
 	force:       false,
 	interactive: false,
+	explain:     false,
+	noHooks:     false,
+	env:         []string{},
+	args:        []string{},
 }
 
 // installCmd installs packages
@@ -55,6 +64,19 @@ NOTE: I currently only work in unix systems (macOS and linux). Windows (pseudo)s
 
 	Install multiple packages:
 	$ fox install <package_name_1> <package_name_2>
+
+	Show which assets were considered and why one was chosen (useful for bug reports):
+	$ fox install <package_name> --explain
+
+	Install a package without running its hooks:
+	$ fox install <package_name> --no-hooks
+
+	Install a package that always runs with some environment variables and arguments:
+	$ fox install <package_name> --env KUBECONFIG=$HOME/.kube/staging --env AWS_PROFILE=staging --arg=--verbose
+
+	They are added to the ones of the package and of the installation being replaced, the ones given here win.
+	Remove a variable, or every argument, of an installation:
+	$ fox install <package_name> --env AWS_PROFILE= --arg=''
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
@@ -63,16 +85,30 @@ NOTE: I currently only work in unix systems (macOS and linux). Windows (pseudo)s
 		}
 
 		if len(args) != 1 && strings.TrimSpace(installFlags.alias) != "" {
-			utils.CheckErr(fmt.Errorf(fmt.Sprintf("I can only install one package when using the --as. flag Given: [%s]", strings.Join(args, ", "))), cmd)
+			checkErr(fmt.Errorf(fmt.Sprintf("I can only install one package when using the --as. flag Given: [%s]", strings.Join(args, ", "))), cmd)
 		}
 
-		interactive := lo.Ternary(installFlags.interactive, false, true)
-		availablePackages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-		utils.CheckErr(err, cmd)
+		env, err := installations.ParseEnv(installFlags.env)
+		checkErr(err, cmd)
 
+		options := fox.InstallOptions{
+			Alias:   installFlags.alias,
+			Force:   installFlags.force,
+			Explain: installFlags.explain,
+			NoHooks: installFlags.noHooks,
+			Env:     env,
+			Args:    installFlags.args,
+		}
+		client := newClient(!installFlags.interactive)
+
+		var results []installOutput
 		if len(args) == 1 {
-			err = installations.InstallPackage(availablePackages, args[0], installFlags.alias, interactive, userConfig, false, installFlags.force)
-			utils.CheckErr(err, cmd)
+			result, err := client.Install(context.Background(), args[0], options)
+			checkErr(err, cmd)
+			if isMachineOutput() {
+				results = append(results, installOutput{Installation: toInstallationOutput(result.Installation), UpToDate: result.UpToDate})
+				checkErr(printOutput(results), cmd)
+			}
 			return
 		}
 
@@ -82,16 +118,21 @@ NOTE: I currently only work in unix systems (macOS and linux). Windows (pseudo)s
 
 		var successfullyInstalled []string
 		for _, p := range args {
-			err = installations.InstallPackage(availablePackages, p, installFlags.alias, interactive, userConfig, false, installFlags.force)
+			result, err := client.Install(context.Background(), p, options)
 			if err != nil {
 				color.Yellow("\n\n There has been an error while installing: " + p)
 				color.Yellow(" The following packages installed successfully:")
 				color.Yellow("    [ " + strings.Join(successfullyInstalled, ", ") + " ]")
 				color.Yellow(" The left over packages were:")
 				color.Yellow("    [ " + strings.Join(utils.DifferenceStrings(args, successfullyInstalled), ", ") + " ]")
-				utils.CheckErr(err, cmd)
+				checkErr(err, cmd)
 			}
 			successfullyInstalled = append(successfullyInstalled, p)
+			results = append(results, installOutput{Installation: toInstallationOutput(result.Installation), UpToDate: result.UpToDate})
+		}
+
+		if isMachineOutput() {
+			checkErr(printOutput(results), cmd)
 		}
 	},
 }
@@ -100,6 +141,10 @@ func init() {
 	installCmd.Flags().StringVar(&installFlags.alias, "as", "", "Install a package and change its executable name\n(to avoid overpopulating your shell config more aliases)")
 	installCmd.Flags().BoolVarP(&installFlags.force, "force", "f", false, "Force the installation of a package even if you are already at the latest version")
 	installCmd.Flags().BoolVarP(&installFlags.interactive, "yes", "y", false, "Do not prompt for confirmation when installing a package")
+	installCmd.Flags().BoolVar(&installFlags.explain, "explain", false, "Print the ranked assets of the release and why each one was, or wasn't, chosen")
+	installCmd.Flags().BoolVar(&installFlags.noHooks, "no-hooks", false, "Do not run the preInstall and postInstall hooks of the package")
+	installCmd.Flags().StringArrayVar(&installFlags.env, "env", []string{}, "An environment variable to run the package with, as NAME=value. NAME= removes it. Can be repeated")
+	installCmd.Flags().StringArrayVar(&installFlags.args, "arg", []string{}, "An argument to always run the package with, eg: --arg=--verbose. --arg='' removes them all. Can be repeated")
 	installCmd.Aliases = []string{"i"}
 	rootCmd.AddCommand(installCmd)
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,12 +11,8 @@ import (
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/ricardofabila/fox/pkg/fox"
 	"github.com/ricardofabila/fox/src/constants"
-	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/repositories"
-	"github.com/ricardofabila/fox/src/types"
-	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
-	"github.com/ricardofabila/fox/src/utils"
 )
 
 type InstalledFlags struct {
@@ -40,9 +37,19 @@ var installedCmd = &cobra.Command{
 			return
 		}
 
-		installs := installations.LoadInstallations()
+		client := newClient(true)
+		installs, err := client.Installed(context.Background())
+		checkErr(err, nil)
 
-		if len(installs.Installations) == 0 {
+		if isMachineOutput() {
+			sort.Slice(installs, func(i, j int) bool {
+				return installs[i].RealName < installs[j].RealName
+			})
+			checkErr(printOutput(toInstallationsOutput(installs)), cmd)
+			return
+		}
+
+		if len(installs) == 0 {
 			fmt.Println()
 			color.Blue("You have no installed packages, sir ヾ(_ _。）")
 			fmt.Println()
@@ -52,29 +59,35 @@ var installedCmd = &cobra.Command{
 		if installedFlags.print {
 			if userConfig.NotifyOutdatedVersions {
 				color.Blue(" ⏳ Loading packages list...")
-				packages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-				utils.CheckErr(err, listCmd)
-				installations.NotifyNewVersions(packages, installs)
+				outdated, err := client.Outdated(context.Background())
+				checkErr(err, listCmd)
+				notifyOutdated(outdated)
 			}
 
 			color.Blue("\n ~(^._.) Listing installed packages:\n\n")
 			fmt.Println("        ______________________________________________________")
 			fmt.Println()
 
-			installs.Installations = lo.Filter(installs.Installations, func(i types.Installation, _ int) bool {
-				return i.IsVisible()
+			installs = lo.Filter(installs, func(i fox.Installation, _ int) bool {
+				return !lo.Contains(constants.DoNotShow, i.ExecutableName)
 			})
-			sort.Slice(installs.Installations, func(i, j int) bool {
-				return installs.Installations[i].RealName < installs.Installations[j].RealName
+			sort.Slice(installs, func(i, j int) bool {
+				return installs[i].RealName < installs[j].RealName
 			})
 
-			for _, i := range installs.Installations {
+			for _, i := range installs {
 				color.Green("        • Package name: " + i.ExecutableName)
 				color.Magenta("	• Version: " + i.Version)
 				if i.Alias != "" {
 					color.Yellow("          Alias: " + i.Alias)
 				}
-				fmt.Println("          Real executable path: " + constants.FoxBinPath + i.RealName)
+				if i.IsPinned() {
+					color.Yellow("          Pinned, 'fox upgrade' skips it")
+				}
+				fmt.Println("          Real executable path: " + lo.Ternary(i.IsWrapped(), i.StorePath, constants.FoxBinPath+i.RealName))
+				if i.IsWrapped() {
+					fmt.Println("          Runs through a wrapper, see 'fox info " + i.ExecutableName + "'")
+				}
 				fmt.Println("          Installed at: " + time.UnixMilli(i.Timestamp).String())
 				fmt.Println("        ______________________________________________________")
 				fmt.Println()
@@ -84,18 +97,20 @@ var installedCmd = &cobra.Command{
 			return
 		}
 
-		packages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-		utils.CheckErr(err, listCmd)
+		packages, err := client.List(context.Background())
+		checkErr(err, listCmd)
 
 		if userConfig.NotifyOutdatedVersions {
-			installations.NotifyNewVersions(packages, installs)
+			outdated, err := client.Outdated(context.Background())
+			checkErr(err, listCmd)
+			notifyOutdated(outdated)
 		}
 
-		packages = lo.Filter(packages, func(p repositoriesTypes.Package, _ int) bool {
+		packages = lo.Filter(packages, func(p fox.Package, _ int) bool {
 			return len(p.InstalledVersions) > 0
 		})
 		err = interactiveRender(packages, "Installed packages")
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 	},
 }
 
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -11,9 +12,7 @@ import (
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
-	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/repositories"
-	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/pkg/fox"
 	"github.com/ricardofabila/fox/src/utils"
 )
 
@@ -31,7 +30,10 @@ var listFlags = ListFlags{
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "See the packages available",
-	Long:  `List the available packages you can install`,
+	Long: `List the available packages you can install.
+
+In the interactive list, select packages with Space, then install (i), uninstall (d) or upgrade (u) them.
+(v) browses the releases of the highlighted package, with their release notes, to install one of them. The progress pane shows what each one does.`,
 	Example: `
 
 	List the packages available in interactive mode.
@@ -45,24 +47,34 @@ var listCmd = &cobra.Command{
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		color.Blue(" ⏳ Loading packages list...")
-		packages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-		utils.CheckErr(err, cmd)
+		client := newClient(true)
+		packages, err := client.List(context.Background())
+		checkErr(err, cmd)
+
+		if isMachineOutput() && listFlags.upgradable {
+			outdated, err := client.Outdated(context.Background())
+			checkErr(err, cmd)
+			checkErr(printOutput(toUpgradesOutput(outdated)), cmd)
+			return
+		}
+
+		if isMachineOutput() {
+			checkErr(printOutput(toPackagesOutput(packages)), cmd)
+			return
+		}
 
 		if userConfig.NotifyOutdatedVersions || listFlags.upgradable {
 			err = checkForNewFoxVersion()
-			utils.CheckErr(err, nil)
-			installs := installations.LoadInstallations()
-			installations.NotifyNewVersions(packages, installs)
+			checkErr(err, nil)
+			outdated, err := client.Outdated(context.Background())
+			checkErr(err, cmd)
+			notifyOutdated(outdated)
 
 			if listFlags.upgradable {
 				return
 			}
 		}
 
-		packages = lo.Filter(packages, func(p repositoriesTypes.Package, _ int) bool {
-			return p.IsVisible()
-		})
-
 		if listFlags.print {
 			color.Blue("Available packages:\n\n")
 			for _, p := range packages {
@@ -76,7 +88,7 @@ var listCmd = &cobra.Command{
 		}
 
 		err = interactiveRender(packages, "Available packages")
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 	},
 }
 
@@ -86,7 +98,7 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 }
 
-func renderDetails(recordToDisplay repositoriesTypes.Package, cb func(string)) string {
+func renderDetails(recordToDisplay fox.Package, cb func(string)) string {
 	fullDetails := "[yellow::b]   " + recordToDisplay.Name
 	if len(recordToDisplay.InstalledVersions) == 0 {
 		fullDetails += "\n\n\t[blue]🦊 Installation:[green] " + "fox i " + recordToDisplay.ExecutableName
@@ -95,7 +107,14 @@ func renderDetails(recordToDisplay repositoriesTypes.Package, cb func(string)) s
 	description := strings.Join(utils.ChunkString(recordToDisplay.Description, 50), "\n    ")
 	fullDetails += fmt.Sprintf("\n\n\t[blue]📄 Description:[white] \n\n    %s", description)
 	fullDetails += fmt.Sprintf("\n\n\t[blue]🔗 URL:[white] \n    " + recordToDisplay.URL)
-	fullDetails += "\n\n\t[blue]🗨️  Main language:[magenta]  " + recordToDisplay.PrimaryLanguage["name"]
+	fullDetails += "\n\n\t[blue]🗨️  Main language:[magenta]  " + recordToDisplay.Language
+
+	if len(recordToDisplay.Executables) > 0 {
+		executables := lo.Map(recordToDisplay.Executables, func(e fox.Executable, _ int) string {
+			return e.InstallName()
+		})
+		fullDetails += "\n\n\t[blue]📦 Also installs:[white] " + strings.Join(executables, ", ")
+	}
 
 	if len(recordToDisplay.DependsOn) > 0 {
 		fullDetails += "\n\n\t[blue]🔒 Depends on:[orange] " + strings.Join(recordToDisplay.DependsOn, ", ")
@@ -116,17 +135,12 @@ func renderDetails(recordToDisplay repositoriesTypes.Package, cb func(string)) s
 }
 
 //gocyclo:ignore
-func interactiveRender(packages []repositoriesTypes.Package, title string) error {
-	// Don't include hidden in the list
-	packages = lo.Filter(packages, func(p repositoriesTypes.Package, _ int) bool {
-		return p.IsVisible()
-	})
-
+func interactiveRender(packages []fox.Package, title string) error {
 	app := tview.NewApplication()
 	menu := tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextColor(tcell.ColorBlue).
-		SetText("[lightgrey:-:-](Esc) to quit, exit search mode\n(Up, Down) to scroll and show details\n(PgUp, PgDn, End, Home) for faster scrolling\n(Ctrl + f or s) to search\n(Enter) to open package URL").
+		SetText("[lightgrey:-:-](Esc) to quit, exit search mode\n(Up, Down) to scroll and show details\n(PgUp, PgDn, End, Home) for faster scrolling\n(Ctrl + f or s) to search\n(Enter) to open package URL\n(Space) to select several packages\n(i) to install, (v) to browse releases\n(d) to uninstall, (u) to upgrade").
 		SetWordWrap(true)
 
 	recordDetails := tview.NewFlex().SetDirection(tview.FlexRow)
@@ -134,23 +148,39 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 
 	detailsText := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
 	recordDetails.AddItem(detailsText, 0, 1, false)
+
+	// what the queued installs, uninstalls and upgrades print
+	progress := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true).ScrollToEnd()
+	progress.SetBorder(true).SetBorderColor(tcell.ColorDarkCyan).SetTitle("Progress")
+	progress.SetChangedFunc(func() {
+		// without blocking, the text changes from the event handlers too
+		go app.Draw()
+	})
+	// the packages selected with Space, by executable name
+	selected := map[string]bool{}
 	reposList := tview.NewList().ShowSecondaryText(true)
 	reposList.SetMainTextColor(tcell.ColorWhite)
 	reposList.SetSecondaryTextColor(tcell.ColorLightSlateGrey)
 
-	fillList := func(listOfRecords []repositoriesTypes.Package) {
-		for _, r := range listOfRecords {
-			if r.Conflicts != "" {
-				reposList.AddItem(" × "+r.Name, "   "+utils.Ellipsis(r.Description, 65), rune(0), nil)
-				continue
-			}
+	itemText := func(r fox.Package) string {
+		if selected[r.ExecutableName] {
+			return " ✓ " + r.Name
+		}
 
-			if len(r.InstalledVersions) > 0 {
-				reposList.AddItem(" ▹ "+r.Name, "   "+utils.Ellipsis(r.Description, 65), rune(0), nil)
-				continue
-			}
+		if r.Conflicts != "" {
+			return " × " + r.Name
+		}
+
+		if len(r.InstalledVersions) > 0 {
+			return " ▹ " + r.Name
+		}
+
+		return " • " + r.Name
+	}
 
-			reposList.AddItem(" • "+r.Name, "   "+utils.Ellipsis(r.Description, 65), rune(0), nil)
+	fillList := func(listOfRecords []fox.Package) {
+		for _, r := range listOfRecords {
+			reposList.AddItem(itemText(r), "   "+utils.Ellipsis(r.Description, 65), rune(0), nil)
 		}
 	}
 
@@ -185,11 +215,11 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 	})
 
 	// search on the original records and create a clone of them
-	originalRecordsToDisplay := make([]repositoriesTypes.Package, len(packages))
+	originalRecordsToDisplay := make([]fox.Package, len(packages))
 	copy(originalRecordsToDisplay, packages)
-	searchField.SetChangedFunc(func(searchTerm string) {
+	applySearch := func(searchTerm string) {
 		if searchTerm != "" {
-			filtered := lo.Filter(originalRecordsToDisplay, func(p repositoriesTypes.Package, _ int) bool {
+			filtered := lo.Filter(originalRecordsToDisplay, func(p fox.Package, _ int) bool {
 				return fuzzy.MatchNormalizedFold(searchTerm, p.Name)
 			})
 
@@ -211,7 +241,42 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 			reposList.Clear()
 			fillList(packages)
 		}
-	})
+	}
+	searchField.SetChangedFunc(applySearch)
+
+	// the messages of fox and of the queued actions go to the progress pane while the list is open
+	previousOutput, previousProgressOutput := color.Output, utils.ProgressOutput
+	output := tview.ANSIWriter(progress)
+	color.Output, utils.ProgressOutput = output, output
+	client := newClientWithOutput(false, output)
+
+	// refresh shows the installed versions after the queued actions, keeping the packages, the search and the current item
+	refresh := func() {
+		fresh, err := client.List(context.Background())
+		if err != nil {
+			color.Red(" " + err.Error())
+			return
+		}
+
+		for i, p := range originalRecordsToDisplay {
+			if updated, found := lo.Find(fresh, func(f fox.Package) bool { return f.ExecutableName == p.ExecutableName }); found {
+				originalRecordsToDisplay[i] = updated
+			}
+		}
+		current := reposList.GetCurrentItem()
+		applySearch(searchField.GetText())
+		if current < reposList.GetItemCount() {
+			reposList.SetCurrentItem(current)
+			renderDetails(packages[current], func(fullDetails string) {
+				detailsText.SetText(fullDetails).SetWordWrap(true).SetWrap(true)
+			})
+		}
+	}
+	actions := newListActions(app, client, progress, refresh)
+	defer func() {
+		actions.stop()
+		color.Output, utils.ProgressOutput = previousOutput, previousProgressOutput
+	}()
 
 	// auto select first item to show details for it on start
 	if reposList.GetItemCount() > 0 {
@@ -233,13 +298,134 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 	container := tview.NewFlex().
 		AddItem(
 			tview.NewFlex().
-				AddItem(recordDetails, 0, 15, false).
-				AddItem(menu, 0, 5, false).
+				AddItem(recordDetails, 0, 12, false).
+				AddItem(progress, 0, 7, false).
+				AddItem(menu, 0, 7, false).
 				SetDirection(tview.FlexRow),
 			0, 4, false).
 		AddItem(reposList, 0, 5, true)
 	flex.AddItem(container, 0, 2, true)
 
+	// the releases browser shows on top of the list, to read the release notes and install a version
+	pages := tview.NewPages().AddPage("list", flex, true, true)
+	closeVersions := func() {
+		pages.RemovePage("versions")
+		app.SetFocus(reposList)
+	}
+	showVersions := func(pkg fox.Package, releases []fox.Release) {
+		notes := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
+		notes.SetBorder(true).SetBorderColor(tcell.ColorMediumOrchid).SetTitle("Release notes")
+		versions := tview.NewList().ShowSecondaryText(true)
+		versions.SetSecondaryTextColor(tcell.ColorLightSlateGrey)
+		versions.SetBorder(true).SetBorderColor(tcell.ColorMediumOrchid).SetTitle(" Releases of " + pkg.ExecutableName + ", (Enter) to install ")
+		versions.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+			_, _, width, _ := notes.GetInnerRect()
+			notes.SetText(tview.TranslateANSI(renderRelease(pkg, releases[index], lo.Ternary(width > 0, width, 80))))
+			notes.ScrollToBeginning()
+		})
+		for _, r := range releases {
+			tag := r.Tag
+			versions.AddItem(" "+tag+lo.Ternary(r.Prerelease, " (prerelease)", ""), "   "+releaseDate(r), rune(0), func() {
+				actions.enqueue(installAction(pkg.ExecutableName + "@" + tag))
+				closeVersions()
+			})
+		}
+
+		// scroll the notes without leaving the list
+		versions.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			row, _ := notes.GetScrollOffset()
+			switch event.Key() {
+			case tcell.KeyPgDn:
+				notes.ScrollTo(row+10, 0)
+				return nil
+			case tcell.KeyPgUp:
+				notes.ScrollTo(lo.Max([]int{row - 10, 0}), 0)
+				return nil
+			}
+
+			return event
+		})
+
+		pages.AddPage("versions", tview.NewFlex().
+			AddItem(versions, 0, 1, true).
+			AddItem(notes, 0, 3, false), true, true)
+		app.SetFocus(versions)
+	}
+
+	// targets are the selected packages, or the highlighted one when none are selected
+	targets := func() []fox.Package {
+		chosen := lo.Filter(originalRecordsToDisplay, func(p fox.Package, _ int) bool {
+			return selected[p.ExecutableName]
+		})
+		if len(chosen) == 0 && reposList.GetItemCount() > 0 {
+			chosen = append(chosen, packages[reposList.GetCurrentItem()])
+		}
+
+		// the selection is spent once the actions are queued
+		if len(selected) > 0 {
+			selected = map[string]bool{}
+			current := reposList.GetCurrentItem()
+			reposList.Clear()
+			fillList(packages)
+			reposList.SetCurrentItem(current)
+		}
+
+		return chosen
+	}
+
+	handleAction := func(key rune) {
+		switch key {
+		case ' ':
+			index := reposList.GetCurrentItem()
+			pkg := packages[index]
+			selected[pkg.ExecutableName] = !selected[pkg.ExecutableName]
+			if !selected[pkg.ExecutableName] {
+				delete(selected, pkg.ExecutableName)
+			}
+			_, secondary := reposList.GetItemText(index)
+			reposList.SetItemText(index, itemText(pkg), secondary)
+		case 'i':
+			for _, p := range targets() {
+				actions.enqueue(installAction(p.ExecutableName))
+			}
+		case 'd':
+			for _, p := range targets() {
+				if len(p.InstalledVersions) == 0 {
+					color.Yellow(" %s is not installed", p.ExecutableName)
+					continue
+				}
+				actions.enqueue(uninstallAction(p.ExecutableName))
+			}
+		case 'u':
+			for _, p := range targets() {
+				if len(p.InstalledVersions) == 0 {
+					color.Yellow(" %s is not installed", p.ExecutableName)
+					continue
+				}
+				actions.enqueue(upgradeAction(p.ExecutableName))
+			}
+		case 'v':
+			pkg := packages[reposList.GetCurrentItem()]
+			color.Cyan(" Loading the releases of " + pkg.ExecutableName)
+			go func() {
+				releases, err := client.Releases(context.Background(), pkg.ExecutableName)
+				if err != nil {
+					color.Red(" ❌ %s", err)
+					return
+				}
+
+				if len(releases) == 0 {
+					color.Yellow(" %s has no releases", pkg.ExecutableName)
+					return
+				}
+
+				app.QueueUpdateDraw(func() {
+					showVersions(pkg, releases)
+				})
+			}()
+		}
+	}
+
 	searching := false
 	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyCtrlS || event.Key() == tcell.KeyCtrlF {
@@ -256,6 +442,14 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 			return event
 		}
 
+		if event.Key() == tcell.KeyRune && lo.Contains([]rune{' ', 'i', 'v', 'd', 'u'}, event.Rune()) {
+			if reposList.GetItemCount() > 0 {
+				handleAction(event.Rune())
+			}
+
+			return nil
+		}
+
 		// Only allow scrolling and selecting using Enter
 		if event.Key() == tcell.KeyEnter ||
 			event.Key() == tcell.KeyUp ||
@@ -274,6 +468,11 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyESC {
+			if pages.HasPage("versions") {
+				closeVersions()
+				return nil
+			}
+
 			if searching {
 				searching = false
 				app.SetFocus(flex)
@@ -286,7 +485,7 @@ func interactiveRender(packages []repositoriesTypes.Package, title string) error
 		return event
 	})
 
-	if err := app.SetRoot(flex, true).EnableMouse(false).Run(); err != nil {
+	if err := app.SetRoot(pages, true).EnableMouse(false).Run(); err != nil {
 		return utils.PrintAndReturnError(fmt.Sprintf("Error starting interactive mode: %s \n", err))
 	}
 
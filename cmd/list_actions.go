@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+	"github.com/rivo/tview"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+)
+
+// listAction is an install, uninstall or upgrade queued from the interactive list
+type listAction struct {
+	description string
+	run         func(ctx context.Context, client *fox.Client) error
+}
+
+// listActions runs the queued actions one at a time, printing what they do in the progress pane
+type listActions struct {
+	app      *tview.Application
+	client   *fox.Client
+	progress *tview.TextView
+	queue    chan listAction
+	pending  int32
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished chan struct{}
+	// done is called from the UI goroutine after every action, eg: to refresh the installed versions
+	done func()
+}
+
+func newListActions(app *tview.Application, client *fox.Client, progress *tview.TextView, done func()) *listActions {
+	ctx, cancel := context.WithCancel(context.Background())
+	actions := &listActions{
+		app:      app,
+		client:   client,
+		progress: progress,
+		queue:    make(chan listAction, 100),
+		ctx:      ctx,
+		cancel:   cancel,
+		finished: make(chan struct{}),
+		done:     done,
+	}
+
+	go actions.work()
+	return actions
+}
+
+func (a *listActions) enqueue(description string, run func(ctx context.Context, client *fox.Client) error) {
+	atomic.AddInt32(&a.pending, 1)
+	select {
+	case a.queue <- listAction{description: description, run: run}:
+	default:
+		atomic.AddInt32(&a.pending, -1)
+		color.Yellow(" Too many queued actions, skipping: " + description)
+	}
+	a.updateTitle()
+}
+
+// stop cancels the running action, waiting for it to clean up, and drops the queued ones
+func (a *listActions) stop() {
+	a.cancel()
+	close(a.queue)
+	<-a.finished
+}
+
+func (a *listActions) work() {
+	defer close(a.finished)
+	for action := range a.queue {
+		if a.ctx.Err() != nil {
+			return
+		}
+
+		color.Cyan(" ▶ " + action.description)
+		err := action.run(a.ctx, a.client)
+		if err != nil {
+			color.Red(" ❌ %s: %s", action.description, err)
+		} else {
+			color.Green(" ✅ " + action.description)
+		}
+		fmt.Fprintln(color.Output)
+
+		atomic.AddInt32(&a.pending, -1)
+		if a.ctx.Err() != nil {
+			continue
+		}
+
+		// without blocking, the application may have stopped already
+		go a.app.QueueUpdateDraw(func() {
+			a.updateTitle()
+			a.done()
+		})
+	}
+}
+
+func (a *listActions) updateTitle() {
+	pending := atomic.LoadInt32(&a.pending)
+	if pending == 0 {
+		a.progress.SetTitle("Progress")
+		return
+	}
+
+	a.progress.SetTitle(fmt.Sprintf("Progress (%d queued)", pending))
+}
+
+func installAction(name string) (string, func(ctx context.Context, client *fox.Client) error) {
+	return "Install " + name, func(ctx context.Context, client *fox.Client) error {
+		_, err := client.Install(ctx, name, fox.InstallOptions{})
+		return err
+	}
+}
+
+func uninstallAction(name string) (string, func(ctx context.Context, client *fox.Client) error) {
+	return "Uninstall " + name, func(ctx context.Context, client *fox.Client) error {
+		_, err := client.Uninstall(ctx, name, fox.UninstallOptions{})
+		return err
+	}
+}
+
+func upgradeAction(name string) (string, func(ctx context.Context, client *fox.Client) error) {
+	return "Upgrade " + name, func(ctx context.Context, client *fox.Client) error {
+		upgraded, err := client.Upgrade(ctx, fox.UpgradeOptions{}, name)
+		if err == nil && len(upgraded) == 0 {
+			color.Blue(" %s is already at the latest version", name)
+		}
+
+		return err
+	}
+}
@@ -1,12 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
-	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/repositories"
-	"github.com/ricardofabila/fox/src/utils"
+	"github.com/ricardofabila/fox/pkg/fox"
 )
 
 // outdatedCmd represents the outdated command
@@ -19,16 +20,37 @@ var outdatedCmd = &cobra.Command{
 	$ fox outdated`,
 	Run: func(cmd *cobra.Command, args []string) {
 		color.Blue(" ⏳ Loading packages list...")
-		packages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, false)
-		utils.CheckErr(err, cmd)
+		outdated, err := newClient(true).Outdated(context.Background())
+		checkErr(err, cmd)
+
+		if isMachineOutput() {
+			checkErr(printOutput(toUpgradesOutput(outdated)), cmd)
+			return
+		}
 
 		err = checkForNewFoxVersion()
-		utils.CheckErr(err, nil)
-		installs := installations.LoadInstallations()
-		installations.NotifyNewVersions(packages, installs)
+		checkErr(err, nil)
+		notifyOutdated(outdated)
 	},
 }
 
+// notifyOutdated prints the installations with a newer version, like installations.NotifyNewVersions
+func notifyOutdated(outdated []fox.Outdated) {
+	color.Magenta(" Checking for available package updates: \n")
+	if len(outdated) == 0 {
+		color.Magenta(" No packages need to be upgraded ~(‾▿‾)~")
+		return
+	}
+
+	for _, o := range outdated {
+		color.Yellow(" " + o.Installation.RealName + " has a newer version: " + o.Package.LatestVersion)
+		color.Yellow("    Your version is: [" + o.Installation.Version + "]")
+		fmt.Println()
+	}
+	color.Yellow(" run 'fox upgrade' to upgrade all packages")
+	fmt.Println()
+}
+
 func init() {
 	rootCmd.AddCommand(outdatedCmd)
 }
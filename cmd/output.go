@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+// The values of the --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+type RootFlags struct {
+	output string
+}
+
+var rootFlags = RootFlags{
+	output: outputText,
+}
+
+// machineOutput is the real stdout when the output is json or yaml, everything meant for humans goes to stderr
+var machineOutput io.Writer = os.Stdout
+
+func isMachineOutput() bool {
+	return rootFlags.output == outputJSON || rootFlags.output == outputYAML
+}
+
+// setupOutput keeps stdout for the json or yaml document, so scripts can pipe it
+func setupOutput() error {
+	switch rootFlags.output {
+	case outputText:
+		return nil
+	case outputJSON, outputYAML:
+	default:
+		format := rootFlags.output
+		rootFlags.output = outputText
+		return fmt.Errorf("Unknown output format '%s', use one of: text, json, yaml", format)
+	}
+
+	machineOutput = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	return nil
+}
+
+// printOutput writes v to stdout in the format of the --output flag
+func printOutput(v interface{}) error {
+	if rootFlags.output == outputYAML {
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		_, err = machineOutput.Write(out)
+		return err
+	}
+
+	encoder := json.NewEncoder(machineOutput)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// The schemas of the json and yaml output. Fields are only ever added to them, never renamed or removed.
+
+type errorOutput struct {
+	Error errorDetails `json:"error" yaml:"error"`
+}
+
+type errorDetails struct {
+	Message string `json:"message" yaml:"message"`
+	// Class is one of: error, network, auth, notFound, stateCorruption, aborted
+	Class    string `json:"class" yaml:"class"`
+	ExitCode int    `json:"exitCode" yaml:"exitCode"`
+}
+
+type releaseOutput struct {
+	Tag       string `json:"tag" yaml:"tag"`
+	Name      string `json:"name" yaml:"name"`
+	CreatedAt string `json:"createdAt" yaml:"createdAt"`
+	// Prerelease releases are never installed as the latest version, only by their tag
+	Prerelease  bool   `json:"prerelease" yaml:"prerelease"`
+	AssetsCount int    `json:"assetsCount" yaml:"assetsCount"`
+	PublishedAt string `json:"publishedAt" yaml:"publishedAt"`
+	// Notes are the release notes, in markdown
+	Notes string `json:"notes" yaml:"notes"`
+	// CompatibleAssets can run on this host, the one fox would install first
+	CompatibleAssets []assetOutput `json:"compatibleAssets" yaml:"compatibleAssets"`
+}
+
+type assetOutput struct {
+	Name string `json:"name" yaml:"name"`
+	Size int    `json:"size" yaml:"size"`
+}
+
+type packageOutput struct {
+	Name              string            `json:"name" yaml:"name"`
+	Repository        string            `json:"repository" yaml:"repository"`
+	ExecutableName    string            `json:"executableName" yaml:"executableName"`
+	Description       string            `json:"description" yaml:"description"`
+	URL               string            `json:"url" yaml:"url"`
+	Language          string            `json:"language" yaml:"language"`
+	LatestVersion     string            `json:"latestVersion" yaml:"latestVersion"`
+	UpdatedAt         string            `json:"updatedAt" yaml:"updatedAt"`
+	InstalledVersions []string          `json:"installedVersions" yaml:"installedVersions"`
+	Executables       []string          `json:"executables" yaml:"executables"`
+	DependsOn         []string          `json:"dependsOn" yaml:"dependsOn"`
+	Conflicts         string            `json:"conflicts" yaml:"conflicts"`
+	Releases          []releaseOutput   `json:"releases" yaml:"releases"`
+	Env               map[string]string `json:"env" yaml:"env"`
+	Args              []string          `json:"args" yaml:"args"`
+}
+
+type searchResultOutput struct {
+	Score   int           `json:"score" yaml:"score"`
+	Package packageOutput `json:"package" yaml:"package"`
+}
+
+type installationOutput struct {
+	Package        string            `json:"package" yaml:"package"`
+	ExecutableName string            `json:"executableName" yaml:"executableName"`
+	Alias          string            `json:"alias" yaml:"alias"`
+	RealName       string            `json:"realName" yaml:"realName"`
+	Version        string            `json:"version" yaml:"version"`
+	Path           string            `json:"path" yaml:"path"`
+	InstalledAt    string            `json:"installedAt" yaml:"installedAt"`
+	SHA256         string            `json:"sha256" yaml:"sha256"`
+	Size           int64             `json:"size" yaml:"size"`
+	Executables    []string          `json:"executables" yaml:"executables"`
+	ShareFiles     []string          `json:"shareFiles" yaml:"shareFiles"`
+	Env            map[string]string `json:"env" yaml:"env"`
+	Args           []string          `json:"args" yaml:"args"`
+	// StorePath is the real executable when the installation runs through a wrapper at Path
+	StorePath string `json:"storePath" yaml:"storePath"`
+	Pinned    bool   `json:"pinned" yaml:"pinned"`
+}
+
+type packageInfoOutput struct {
+	Package       packageOutput        `json:"package" yaml:"package"`
+	Installations []installationOutput `json:"installations" yaml:"installations"`
+}
+
+type installOutput struct {
+	Installation installationOutput `json:"installation" yaml:"installation"`
+	// UpToDate is true when the package was already installed at the latest version and nothing changed
+	UpToDate bool `json:"upToDate" yaml:"upToDate"`
+}
+
+// upgradeOutput is an installation that can be, or was, upgraded
+type upgradeOutput struct {
+	RealName       string `json:"realName" yaml:"realName"`
+	Package        string `json:"package" yaml:"package"`
+	CurrentVersion string `json:"currentVersion" yaml:"currentVersion"`
+	LatestVersion  string `json:"latestVersion" yaml:"latestVersion"`
+}
+
+// planEntryOutput is what upgrading does to an installation, status is upgrade, upToDate or held
+type planEntryOutput struct {
+	RealName            string       `json:"realName" yaml:"realName"`
+	Package             string       `json:"package" yaml:"package"`
+	Status              string       `json:"status" yaml:"status"`
+	CurrentVersion      string       `json:"currentVersion" yaml:"currentVersion"`
+	TargetVersion       string       `json:"targetVersion" yaml:"targetVersion"`
+	Asset               *assetOutput `json:"asset" yaml:"asset"`
+	HeldBecause         string       `json:"heldBecause,omitempty" yaml:"heldBecause,omitempty"`
+	Problem             string       `json:"problem,omitempty" yaml:"problem,omitempty"`
+	MissingDependencies []string     `json:"missingDependencies" yaml:"missingDependencies"`
+	Dependents          []string     `json:"dependents" yaml:"dependents"`
+}
+
+type doctorCheckOutput struct {
+	Section string `json:"section" yaml:"section"`
+	Name    string `json:"name" yaml:"name"`
+	// Status is one of: ok, warning, problem
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type doctorOutput struct {
+	Checks   []doctorCheckOutput `json:"checks" yaml:"checks"`
+	Warnings int                 `json:"warnings" yaml:"warnings"`
+	Problems int                 `json:"problems" yaml:"problems"`
+}
+
+type driftOutput struct {
+	Clean     bool                 `json:"clean" yaml:"clean"`
+	Missing   []installationOutput `json:"missing" yaml:"missing"`
+	Modified  []installationOutput `json:"modified" yaml:"modified"`
+	Untracked []string             `json:"untracked" yaml:"untracked"`
+	Unchecked []installationOutput `json:"unchecked" yaml:"unchecked"`
+}
+
+// toPackageOutput is the package with the releases that were fetched for it, if any
+func toPackageOutput(p fox.Package, releases []fox.Release) packageOutput {
+	return packageOutput{
+		Name:              p.Name,
+		Repository:        p.NameWithOwner,
+		ExecutableName:    p.ExecutableName,
+		Description:       p.Description,
+		URL:               p.URL,
+		Language:          p.Language,
+		LatestVersion:     p.LatestVersion,
+		UpdatedAt:         p.UpdatedAt,
+		InstalledVersions: lo.Ternary(p.InstalledVersions == nil, []string{}, p.InstalledVersions),
+		Executables: lo.Map(p.Executables, func(e fox.Executable, _ int) string {
+			return e.InstallName()
+		}),
+		DependsOn: lo.Ternary(p.DependsOn == nil, []string{}, p.DependsOn),
+		Conflicts: p.Conflicts,
+		Releases:  toReleasesOutput(p, releases),
+		Env:       lo.Ternary(p.Env == nil, map[string]string{}, p.Env),
+		Args:      lo.Ternary(p.Args == nil, []string{}, p.Args),
+	}
+}
+
+func toReleasesOutput(p fox.Package, releases []fox.Release) []releaseOutput {
+	if len(releases) == 0 {
+		return []releaseOutput{}
+	}
+
+	return lo.Map(releases, func(r fox.Release, _ int) releaseOutput {
+		return releaseOutput{
+			Tag:         r.Tag,
+			Name:        r.Name,
+			CreatedAt:   r.CreatedAt,
+			Prerelease:  r.Prerelease,
+			AssetsCount: len(r.Assets),
+			PublishedAt: r.PublishedAt,
+			Notes:       r.Body,
+			CompatibleAssets: lo.Map(fox.CompatibleAssets(p, r), func(a fox.Asset, _ int) assetOutput {
+				return assetOutput{Name: a.Name, Size: a.Size}
+			}),
+		}
+	})
+}
+
+func toInstallationOutput(i fox.Installation) installationOutput {
+	return installationOutput{
+		Package:        i.Package,
+		ExecutableName: i.ExecutableName,
+		Alias:          i.Alias,
+		RealName:       i.RealName,
+		Version:        i.Version,
+		Path:           constants.FoxBinPath + i.RealName,
+		InstalledAt:    time.UnixMilli(i.Timestamp).UTC().Format(time.RFC3339),
+		SHA256:         i.SHA256,
+		Size:           i.Size,
+		Executables:    lo.Map(i.Executables, func(e fox.InstalledExecutable, _ int) string { return e.Name }),
+		ShareFiles:     lo.Ternary(i.ShareFiles == nil, []string{}, i.ShareFiles),
+		Env:            lo.Ternary(i.Env == nil, map[string]string{}, i.Env),
+		Args:           lo.Ternary(i.Args == nil, []string{}, i.Args),
+		StorePath:      i.StorePath,
+		Pinned:         i.IsPinned(),
+	}
+}
+
+func toInstallationsOutput(installs []fox.Installation) []installationOutput {
+	return lo.Map(installs, func(i fox.Installation, _ int) installationOutput {
+		return toInstallationOutput(i)
+	})
+}
+
+func toPackagesOutput(packages []fox.Package) []packageOutput {
+	return lo.Map(packages, func(p fox.Package, _ int) packageOutput {
+		return toPackageOutput(p, nil)
+	})
+}
+
+func toUpgradesOutput(outdated []fox.Outdated) []upgradeOutput {
+	return lo.Map(outdated, func(o fox.Outdated, _ int) upgradeOutput {
+		return upgradeOutput{
+			RealName:       o.Installation.RealName,
+			Package:        o.Installation.Package,
+			CurrentVersion: o.Installation.Version,
+			LatestVersion:  o.Package.LatestVersion,
+		}
+	})
+}
+
+func toPlanOutput(plan fox.UpgradePlan) []planEntryOutput {
+	return lo.Map(plan, func(e fox.PlanEntry, _ int) planEntryOutput {
+		var asset *assetOutput
+		if e.Asset != nil {
+			asset = &assetOutput{Name: e.Asset.Name, Size: e.Asset.Size}
+		}
+
+		return planEntryOutput{
+			RealName:            e.Installation.RealName,
+			Package:             e.Installation.Package,
+			Status:              e.Status,
+			CurrentVersion:      e.Installation.Version,
+			TargetVersion:       e.Target,
+			Asset:               asset,
+			HeldBecause:         e.HeldBecause,
+			Problem:             e.Problem,
+			MissingDependencies: lo.Ternary(e.MissingDependencies == nil, []string{}, e.MissingDependencies),
+			Dependents:          lo.Ternary(e.Dependents == nil, []string{}, e.Dependents),
+		}
+	})
+}
@@ -11,8 +11,8 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/installations"
 	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
-	"github.com/ricardofabila/fox/src/utils"
 )
 
 type PackageFlags struct {
@@ -20,6 +20,13 @@ type PackageFlags struct {
 	executableName string
 	kind           string // type is a keyword
 	dependsOn      string
+	executables    string
+	assets         []string
+	binaryPath     string
+	test           string
+	hooks          repositoriesTypes.Hooks
+	env            []string
+	args           []string
 }
 
 var packageFlags = PackageFlags{
@@ -27,6 +34,13 @@ var packageFlags = PackageFlags{
 	executableName: "",
 	kind:           "",
 	dependsOn:      "",
+	executables:    "",
+	assets:         []string{},
+	binaryPath:     "",
+	test:           "",
+	hooks:          repositoriesTypes.Hooks{},
+	env:            []string{},
+	args:           []string{},
 }
 
 // packageCmd represents the package command
@@ -37,29 +51,44 @@ var packageCmd = &cobra.Command{
 	Add a package entry to your repositories.yaml file
 	Add a package:
 	$ fox add package --path="OWNER/REPO" --executableName="a-name" --type="script" --dependsOn="bash,curl"
+
+	Add a package that ships more than one executable in its release archive:
+	$ fox add package --path="OWNER/REPO" --executableName="a-name" --type="binary" --executables="a-helper,another-helper:renamed"
+
+	Add a package and declare which asset to download for each OS and architecture:
+	$ fox add package --path="OWNER/REPO" --executableName="a-name" --type="binary" \
+		--asset="linux/amd64=a-name_{{.Version}}_linux_x86_64.tar.gz" \
+		--asset="darwin/*=regex:a-name_.*_(darwin|macos)_all\.zip" \
+		--binaryPath="a-name_{{.Version}}/bin/a-name"
+
+	Add a package with a command that checks it works after installing it:
+	$ fox add package --path="OWNER/REPO" --executableName="a-name" --type="binary" --test="a-name --version"
+
+	Add a package with hooks, run with only PATH, HOME, FOX_PACKAGE, FOX_VERSION and FOX_BIN set:
+	$ fox add package --path="OWNER/REPO" --executableName="a-name" --type="binary" --postInstall='"$FOX_BIN" init'
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) > 0 {
-			utils.CheckErr(fmt.Errorf(fmt.Sprintf("'package' takes not arguments, given: [%s]", strings.Join(args, ", "))), cmd)
+			checkErr(fmt.Errorf(fmt.Sprintf("'package' takes not arguments, given: [%s]", strings.Join(args, ", "))), cmd)
 		}
 
 		packageFlags.path = strings.TrimSpace(packageFlags.path)
 		if packageFlags.path == "" {
-			utils.CheckErr(fmt.Errorf("--path is required, and can't be empty"), cmd)
+			checkErr(fmt.Errorf("--path is required, and can't be empty"), cmd)
 		}
 
 		packageFlags.executableName = strings.TrimSpace(packageFlags.executableName)
 		if packageFlags.executableName == "" {
-			utils.CheckErr(fmt.Errorf("--executableName is required, and can't be empty"), cmd)
+			checkErr(fmt.Errorf("--executableName is required, and can't be empty"), cmd)
 		}
 
 		packageFlags.kind = strings.TrimSpace(packageFlags.kind)
 		if packageFlags.kind == "" {
-			utils.CheckErr(fmt.Errorf("--kind is required, and can't be empty"), cmd)
+			checkErr(fmt.Errorf("--kind is required, and can't be empty"), cmd)
 		}
 
 		if !lo.Contains([]string{"script", "binary"}, packageFlags.kind) {
-			utils.CheckErr(fmt.Errorf("error, the package type '"+packageFlags.kind+"' is not supported. Only 'script' and 'binary' are valid values."), cmd)
+			checkErr(fmt.Errorf("error, the package type '"+packageFlags.kind+"' is not supported. Only 'script' and 'binary' are valid values."), cmd)
 		}
 
 		packageFlags.dependsOn = strings.TrimSpace(packageFlags.dependsOn)
@@ -67,7 +96,7 @@ var packageCmd = &cobra.Command{
 
 		// read the repositories.yaml file and add to the list
 		home, err := os.UserHomeDir()
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("repositories")
@@ -81,10 +110,47 @@ var packageCmd = &cobra.Command{
 			configPackage.DependsOn = dependsOn
 		}
 
+		// name or name:as
+		for _, e := range strings.Split(packageFlags.executables, ",") {
+			parts := strings.SplitN(strings.TrimSpace(e), ":", 2)
+			if parts[0] == "" {
+				continue
+			}
+
+			executable := repositoriesTypes.Executable{Name: parts[0]}
+			if len(parts) == 2 {
+				executable.As = parts[1]
+			}
+
+			configPackage.Executables = append(configPackage.Executables, executable)
+		}
+
+		// os/arch=template
+		for _, a := range packageFlags.assets {
+			parts := strings.SplitN(strings.TrimSpace(a), "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+				checkErr(fmt.Errorf("--asset must follow the format os/arch=template, given: "+a), cmd)
+			}
+
+			if configPackage.Assets == nil {
+				configPackage.Assets = map[string]string{}
+			}
+			configPackage.Assets[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+
+		configPackage.BinaryPath = strings.TrimSpace(packageFlags.binaryPath)
+		configPackage.Test = strings.TrimSpace(packageFlags.test)
+		configPackage.Hooks = packageFlags.hooks
+		configPackage.Args = packageFlags.args
+		if len(packageFlags.env) > 0 {
+			configPackage.Env, err = installations.ParseEnv(packageFlags.env)
+			checkErr(err, cmd)
+		}
+
 		// check for duplicates
 		for _, p := range repositoriesConfig.Packages {
 			if strings.EqualFold(p.Path, packageFlags.path) {
-				utils.CheckErr(fmt.Errorf("the package with the path '"+packageFlags.path+"' already exists"), nil)
+				checkErr(fmt.Errorf("the package with the path '"+packageFlags.path+"' already exists"), nil)
 			}
 		}
 
@@ -92,7 +158,7 @@ var packageCmd = &cobra.Command{
 		viper.Set("remotes", repositoriesConfig.Remotes)
 		viper.Set("packages", repositoriesConfig.Packages)
 		err = viper.WriteConfig()
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		viper.Reset() // reset viper since we use it for different config files
 		color.Green(" 🦊 package added, Run 'fox update' to rebuild the cache.")
 	},
@@ -103,5 +169,14 @@ func init() {
 	packageCmd.Flags().StringVar(&packageFlags.executableName, "executableName", "", "The name the package will install as by default")
 	packageCmd.Flags().StringVar(&packageFlags.kind, "type", "", "The type of the remote. It can be one of: binary|script")
 	packageCmd.Flags().StringVar(&packageFlags.dependsOn, "dependsOn", "", "(optional) - a comma separated list of dependencies")
+	packageCmd.Flags().StringArrayVar(&packageFlags.assets, "asset", []string{}, "(optional) - the asset to download for an os/arch, as os/arch=template. Can be repeated.\nTemplates are globs, or regular expressions when prefixed with 'regex:', that match the whole asset name and can use {{.Version}}, {{.Tag}}, {{.OS}} and {{.Arch}}")
+	packageCmd.Flags().StringVar(&packageFlags.binaryPath, "binaryPath", "", "(optional) - the path of the executable inside the release archive, it can use the same templates as --asset")
+	packageCmd.Flags().StringVar(&packageFlags.test, "test", "", "(optional) - a command that checks the executable works after installing it, eg: 'a-name --version'")
+	packageCmd.Flags().StringVar(&packageFlags.hooks.PreInstall, "preInstall", "", "(optional) - a shell command to run before installing the package")
+	packageCmd.Flags().StringVar(&packageFlags.hooks.PostInstall, "postInstall", "", "(optional) - a shell command to run after installing the package")
+	packageCmd.Flags().StringVar(&packageFlags.hooks.PreUninstall, "preUninstall", "", "(optional) - a shell command to run before uninstalling the package")
+	packageCmd.Flags().StringArrayVar(&packageFlags.env, "env", []string{}, "(optional) - an environment variable the executable runs with, as NAME=value. Can be repeated")
+	packageCmd.Flags().StringArrayVar(&packageFlags.args, "arg", []string{}, "(optional) - an argument the executable always runs with, eg: --arg=--verbose. Can be repeated")
+	packageCmd.Flags().StringVar(&packageFlags.executables, "executables", "", "(optional) - a comma separated list of extra executables in the release archive.\nUse name:as to install one with a different name")
 	addCmd.AddCommand(packageCmd)
 }
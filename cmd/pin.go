@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// pinCmd keeps installations at their version
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Keep an installation at its version when upgrading",
+	Long: `
+ 'fox upgrade' skips pinned installations, run 'fox unpin' to upgrade them again.
+ Installations named after their version, eg: tool@v1.0.0, are always pinned.
+`,
+	Example: `
+	Pin a package:
+	$ fox pin <package_name>
+
+	Pin a package that was installed with a different name using the --as flag during 'fox install':
+	$ fox pin <custom_name>
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPin(cmd, args, true)
+	},
+}
+
+// unpinCmd lets installations be upgraded again
+var unpinCmd = &cobra.Command{
+	Use:   "unpin",
+	Short: "Let 'fox upgrade' upgrade a pinned installation again",
+	Example: `
+	Unpin a package:
+	$ fox unpin <package_name>
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPin(cmd, args, false)
+	},
+}
+
+func runPin(cmd *cobra.Command, args []string, pinned bool) {
+	if len(args) == 0 {
+		_ = cmd.Help()
+		return
+	}
+
+	if len(args) != 1 {
+		checkErr(fmt.Errorf("I only support one argument. Given: [%s]", strings.Join(args, ", ")), cmd)
+	}
+
+	client := newClient(true)
+	pin := client.Unpin
+	if pinned {
+		pin = client.Pin
+	}
+
+	installation, err := pin(context.Background(), strings.TrimSpace(args[0]))
+	checkErr(err, cmd)
+	if isMachineOutput() {
+		checkErr(printOutput(toInstallationOutput(installation)), cmd)
+		return
+	}
+
+	if pinned {
+		color.Green(" 📌 Pinned %s at %s", installation.RealName, installation.Version)
+	} else {
+		color.Green(" Unpinned %s, 'fox upgrade' will upgrade it", installation.RealName)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
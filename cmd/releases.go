@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+	"github.com/ricardofabila/fox/src/installations"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// releaseDate is the day the release was published, as GitHub doesn't always send it, the creation day otherwise
+func releaseDate(r fox.Release) string {
+	date := lo.Ternary(r.PublishedAt != "", r.PublishedAt, r.CreatedAt)
+	parsed, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return date
+	}
+
+	return parsed.Local().Format("Jan 2, 2006")
+}
+
+// renderRelease renders a release for the terminal: its date, the assets this host can run and the release notes
+func renderRelease(pkg fox.Package, release fox.Release, width int) string {
+	var b strings.Builder
+	title := color.New(color.Bold, color.FgYellow).Sprint(release.Tag)
+	if release.Name != "" && release.Name != release.Tag {
+		title += " " + release.Name
+	}
+	b.WriteString(" " + title)
+	if date := releaseDate(release); date != "" {
+		b.WriteString(color.New(color.Faint).Sprint("  published " + date))
+	}
+	if release.Prerelease {
+		b.WriteString(color.YellowString("  (prerelease)"))
+	}
+	if lo.Contains(pkg.InstalledVersions, release.Tag) {
+		b.WriteString(color.GreenString("  (installed)"))
+	}
+	b.WriteString("\n\n")
+
+	host := installations.CurrentHost()
+	compatible := fox.CompatibleAssets(pkg, release)
+	if len(compatible) == 0 {
+		b.WriteString(color.RedString("  No assets for %s", host) + "\n")
+	} else {
+		b.WriteString(color.BlueString("  Assets for %s:", host) + "\n")
+		for i, a := range compatible {
+			marker := lo.Ternary(i == 0, color.GreenString("★"), " ")
+			b.WriteString(fmt.Sprintf("   %s %s  %s\n", marker, a.Name, color.New(color.Faint).Sprint(utils.ByteCountIEC(int64(a.Size)))))
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(renderReleaseNotes(release, width))
+	return b.String()
+}
+
+// renderReleaseNotes renders the notes of a release indented, with the lines about breaking changes in red
+func renderReleaseNotes(release fox.Release, width int) string {
+	notes := strings.TrimSpace(release.Body)
+	if notes == "" {
+		notes = "_No release notes_"
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(utils.RenderMarkdown(notes, width-4), "\n") {
+		if installations.IsBreaking(utils.StripANSI(line)) {
+			b.WriteString(color.New(color.FgHiRed, color.Bold).Sprint("⚠ "+utils.StripANSI(line)) + "\n")
+			continue
+		}
+
+		b.WriteString("  " + line + "\n")
+	}
+
+	return b.String()
+}
+
+// printChangelog prints the release notes of the releases an upgrade goes through, newest first
+func printChangelog(o fox.Outdated, releases []fox.Release, found bool) {
+	color.Magenta(" 📜 What changed in %s from %s to %s:", o.Installation.RealName, o.Installation.Version, o.Package.LatestVersion)
+	if !found {
+		color.Yellow(" Could not find %s among the releases, showing all of them", o.Installation.Version)
+	}
+
+	if fox.HasBreakingChanges(releases) {
+		color.New(color.FgHiRed, color.Bold).Printf(" ⚠ %s has breaking changes, read the lines in red before upgrading\n", o.Installation.RealName)
+	}
+	fmt.Println()
+
+	if len(releases) == 0 {
+		color.Yellow("   No releases found")
+		fmt.Println()
+		return
+	}
+
+	for _, r := range releases {
+		title := color.New(color.Bold, color.FgYellow).Sprint(r.Tag)
+		if date := releaseDate(r); date != "" {
+			title += color.New(color.Faint).Sprint("  published " + date)
+		}
+		fmt.Println("  " + title)
+		fmt.Println()
+		for _, line := range strings.Split(strings.TrimRight(renderReleaseNotes(r, 80), "\n"), "\n") {
+			fmt.Println("  " + line)
+		}
+		fmt.Println()
+	}
+}
+
+// printReleases prints the releases of the package, newest first
+func printReleases(pkg fox.Package, releases []fox.Release) {
+	if len(releases) == 0 {
+		color.Yellow(" %s has no releases", pkg.ExecutableName)
+		return
+	}
+
+	color.Magenta(" Releases of %s:\n\n", pkg.ExecutableName)
+	for _, r := range releases {
+		fmt.Print(renderRelease(pkg, r, 80))
+		fmt.Println("  ______________________________________________________")
+		fmt.Println()
+	}
+}
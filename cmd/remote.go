@@ -12,7 +12,6 @@ import (
 
 	"github.com/ricardofabila/fox/src/constants"
 	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
-	"github.com/ricardofabila/fox/src/utils"
 )
 
 type RemoteFlags struct {
@@ -32,34 +31,34 @@ var remoteCmd = &cobra.Command{
 	Long:  `Add a remote to your repositories.yaml file`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) > 0 {
-			utils.CheckErr(fmt.Errorf(fmt.Sprintf("'remote' takes not arguments, given: [%s]", strings.Join(args, ", "))), cmd)
+			checkErr(fmt.Errorf(fmt.Sprintf("'remote' takes not arguments, given: [%s]", strings.Join(args, ", "))), cmd)
 		}
 
 		remoteFlags.url = strings.TrimSpace(remoteFlags.url)
 		if remoteFlags.url == "" {
-			utils.CheckErr(fmt.Errorf("--url is required, and can't be empty"), cmd)
+			checkErr(fmt.Errorf("--url is required, and can't be empty"), cmd)
 		}
 
 		remoteFlags.kind = strings.TrimSpace(remoteFlags.kind)
 		if remoteFlags.kind == "" {
-			utils.CheckErr(fmt.Errorf("--kind is required, and can't be empty"), cmd)
+			checkErr(fmt.Errorf("--kind is required, and can't be empty"), cmd)
 		}
 
 		if !lo.Contains([]string{"github", "open"}, remoteFlags.kind) {
-			utils.CheckErr(fmt.Errorf("error, the remote type '"+remoteFlags.kind+"' is not supported. Only 'github' and 'open' are valid values."), cmd)
+			checkErr(fmt.Errorf("error, the remote type '"+remoteFlags.kind+"' is not supported. Only 'github' and 'open' are valid values."), cmd)
 		}
 
 		// check for duplicates
 		for _, remote := range repositoriesConfig.Remotes {
 			if strings.EqualFold(remote.URL, remoteFlags.url) && strings.EqualFold(remote.Type, remoteFlags.kind) {
-				utils.CheckErr(fmt.Errorf("the remote with the url '"+remoteFlags.url+"' "+
+				checkErr(fmt.Errorf("the remote with the url '"+remoteFlags.url+"' "+
 					"and the type '"+remoteFlags.kind+"' already exists"), nil)
 			}
 		}
 
 		// read the repositories.yaml file and add to the list
 		home, err := os.UserHomeDir()
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("repositories")
@@ -67,7 +66,7 @@ var remoteCmd = &cobra.Command{
 		viper.Set("remotes", repositoriesConfig.Remotes)
 		viper.Set("packages", repositoriesConfig.Packages)
 		err = viper.WriteConfig()
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		viper.Reset() // reset viper since we use it for different config files
 		color.Green(" 🦊 remote added, Run 'fox update' to rebuild the cache.")
 	},
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Reinstall packages whose executables are missing or were modified",
+	Long: `
+Reinstall the recorded version of every installation that 'fox verify' reports
+as missing or modified, keeping its name.
+
+Untracked files are left alone, fox never deletes what it didn't install.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			checkErr(fmt.Errorf("'repair' takes no arguments, given: %v", args), cmd)
+		}
+
+		client := newClient(true)
+		drift, err := client.Verify(context.Background())
+		checkErr(err, cmd)
+
+		broken := drift.Broken()
+		if len(broken) == 0 {
+			fmt.Println()
+			color.Green(" ✅ Nothing to repair.")
+			fmt.Println()
+			return
+		}
+
+		var failed []string
+		for _, installation := range broken {
+			color.Blue(" Repairing: %s@%s", installation.RealName, installation.Version)
+			_, err = client.Repair(context.Background(), installation.RealName)
+			if err != nil {
+				color.Red(" Could not repair %s: %s", installation.RealName, err.Error())
+				failed = append(failed, installation.RealName)
+			}
+			fmt.Println()
+		}
+
+		if len(failed) > 0 {
+			checkErr(fmt.Errorf("could not repair: %v", failed), nil)
+		}
+
+		color.Green(" ✅ Repair complete!")
+		fmt.Println()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
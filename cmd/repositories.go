@@ -21,13 +21,13 @@ var repositoriesCmd = &cobra.Command{
 	Long:  `Print your repositories file`,
 	Run: func(cmd *cobra.Command, args []string) {
 		out, err := yaml.Marshal(&repositoriesConfig)
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		fmt.Println()
 		color.Blue("          This is your repositories configuration, sir.")
 		color.Blue("          Located at '~" + constants.RepositoriesFilePath + "'.")
 		color.Blue(" ( ^-^)_旦\n\n")
 		err = utils.PrintYAML(string(out))
-		utils.CheckErr(err, cmd)
+		checkErr(err, cmd)
 		fmt.Println()
 	},
 }
@@ -41,35 +41,39 @@ func initRepositories() {
 	// create config file if it doesn't exist
 	if !utils.FileExistsInHome(constants.RepositoriesFilePath) {
 		err := writeDefaultRepositoriesFile()
-		utils.CheckErr(err, repositoriesCmd)
+		checkErr(err, repositoriesCmd)
 	}
 
 	// Find home directory.
 	home, err := os.UserHomeDir()
-	utils.CheckErr(err, repositoriesCmd)
+	checkErr(err, repositoriesCmd)
 
 	viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("repositories")
 
 	err = viper.ReadInConfig()
-	utils.CheckErr(err, repositoriesCmd)
+	checkErr(err, repositoriesCmd)
 
 	err = viper.Unmarshal(&repositoriesConfig)
-	utils.CheckErr(err, repositoriesCmd)
+	checkErr(err, repositoriesCmd)
 	viper.Reset() // reset viper since we use it for different config files
 	// fmt.Printf("%v", repositoriesConfig)
 }
 
 // writeDefaultRepositoriesFile Creates a default repositories file
 func writeDefaultRepositoriesFile() error {
-	utils.CreateDirectoryIfNotExistsInHome(constants.ConfigDirectoryPath)
-	err := utils.CreateFileIfNotExistsInHome(constants.RepositoriesFilePath)
-	utils.CheckErr(err, nil)
+	err := utils.CreateDirectoryIfNotExistsInHome(constants.ConfigDirectoryPath)
+	if err != nil {
+		return err
+	}
+
+	err = utils.CreateFileIfNotExistsInHome(constants.RepositoriesFilePath)
+	checkErr(err, nil)
 
 	// Find home directory.
 	home, err := os.UserHomeDir()
-	utils.CheckErr(err, repositoriesCmd)
+	checkErr(err, repositoriesCmd)
 	viper.AddConfigPath(home + constants.ConfigDirectoryPath)
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("repositories")
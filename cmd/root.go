@@ -45,7 +45,16 @@ var rootCmd = &cobra.Command{
              ⠀⠀⠀⠙⠿⣿⣿⣿⣿⣿⣿⣿⡿⠿⠛⠋⠉⠁⠀⠀⠀⠀⠈⠛⠃⠀⠀⠀ ⠀
              ⠀⠀⠀⠀⠀⠀⠉⠉⠉⠉⠉⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀
 
-    fox is a (simple!) package manager to install your own tools with ease`,
+    fox is a (simple!) package manager to install your own tools with ease
+
+Exit codes:
+  1    error
+  2    upgrades available, with 'fox upgrade --dry-run'
+  3    network error, a server can't be reached or fails
+  4    authentication error, run 'gh auth login' or check your token
+  5    not found, the package, version or asset doesn't exist
+  6    corrupted state, a file fox keeps in ` + constants.FoxRootPath + ` can't be read
+  130  aborted`,
 	Version: VERSION,
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
@@ -66,10 +75,25 @@ func Execute() {
 	})
 	build.Boostrap()
 
+	// the output format is needed before running the command, to keep every message out of the json or yaml
+	if c, args, err := rootCmd.Find(os.Args[1:]); err == nil {
+		_ = c.ParseFlags(args)
+
+		// 'fox run' leaves stdout to the package it runs, so pipes work
+		if c == runCmd {
+			color.Output = os.Stderr
+			c.SetOut(os.Stderr)
+		}
+	}
+	checkErr(setupOutput(), nil)
+
 	// Check that gh is installed
 	_, err := execabs.LookPath("gh")
 	if err != nil {
 		if len(os.Args) == 1 || (len(os.Args) > 1 && os.Args[1] != "gh") {
+			if isMachineOutput() {
+				checkErr(fmt.Errorf("gh is not installed or is not in your $PATH, run 'fox gh' to install it"), nil)
+			}
 			color.Yellow("\n Looks like you don't have gh installed or is not in your $PATH.\n\n")
 			color.Yellow("\n I can install it for you, just run `fox gh`.\n\n")
 			os.Exit(1)
@@ -89,6 +113,7 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&rootFlags.output, "output", "o", outputText, "The format of the output: text, json or yaml. With json and yaml, messages go to stderr")
 	cobra.OnInitialize()
 	initRepositories()
 	initConfig()
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+)
+
+type RunFlags struct {
+	explain bool
+}
+
+var runFlags = RunFlags{
+	explain: false,
+}
+
+// runCmd runs packages without installing them
+var runCmd = &cobra.Command{
+	Use:   "run <package_name>[@<version>] -- [args...]",
+	Short: "Run a package without installing it",
+	Long: `
+Run a package without installing it, useful for one-off usage in scripts and CI.
+
+The executable is kept in the downloads cache, see 'fox cache', so running the same
+version again doesn't download it again. Nothing is added to your installations
+and nothing is written to /usr/local/Fox/bin/.
+
+Everything fox prints goes to stderr, stdout is left to the package.
+`,
+	Example: `
+	Run the latest version of a package:
+	$ fox run <package_name> -- --help
+
+	Run a specific version of a package:
+	$ fox run <package_name>@v1.0.3 -- <args>
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dash := cmd.ArgsLenAtDash()
+		if dash > 1 {
+			checkErr(fmt.Errorf("I can only run one package, use -- before its arguments. Given: %v", args[:dash]), cmd)
+		}
+
+		// only returns if it couldn't run the package
+		err := newClient(false).Run(context.Background(), args[0], args[1:], fox.RunOptions{Explain: runFlags.explain})
+		checkErr(err, cmd)
+	},
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&runFlags.explain, "explain", false, "Print the ranked assets of the release and why each one was, or wasn't, chosen")
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+type SearchFlags struct {
+	installed bool
+	language  string
+	owner     string
+	limit     int
+}
+
+var searchFlags = SearchFlags{
+	installed: false,
+	language:  "",
+	owner:     "",
+	limit:     0,
+}
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the available packages",
+	Long: `Search the available packages by executable name, repository, description and language.
+The best matches are printed first, without the interactive view of 'fox list'.`,
+	Example: `
+	Search for a package:
+	$ fox search kube
+
+	Search the descriptions with several words:
+	$ fox search "json processor"
+
+	Search the installed packages written in Go:
+	$ fox search --installed --language go
+
+	Search the packages of an organization, as JSON:
+	$ fox search --owner cli -o json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+		filters := fox.SearchFilters{
+			Installed: searchFlags.installed,
+			Language:  searchFlags.language,
+			Owner:     searchFlags.owner,
+		}
+
+		if strings.TrimSpace(query) == "" && filters == (fox.SearchFilters{}) {
+			_ = cmd.Help()
+			return
+		}
+
+		results, err := newClient(true).Search(context.Background(), query, filters)
+		checkErr(err, cmd)
+
+		if searchFlags.limit > 0 && len(results) > searchFlags.limit {
+			results = results[:searchFlags.limit]
+		}
+
+		if isMachineOutput() {
+			checkErr(printOutput(lo.Map(results, func(r fox.SearchResult, _ int) searchResultOutput {
+				return searchResultOutput{Score: r.Score, Package: toPackageOutput(r.Package, nil)}
+			})), cmd)
+			return
+		}
+
+		if len(results) == 0 {
+			color.Yellow(" No packages found. Try running 'fox update' first.")
+			color.Yellow(" ٩(๏̯๏)۶")
+			return
+		}
+
+		printSearchResults(results)
+	},
+}
+
+func init() {
+	searchCmd.Flags().BoolVar(&searchFlags.installed, "installed", false, "Only search the installed packages")
+	searchCmd.Flags().StringVar(&searchFlags.language, "language", "", "Only search the packages written in a language, eg: go")
+	searchCmd.Flags().StringVar(&searchFlags.owner, "owner", "", "Only search the packages of a GitHub user or organization")
+	searchCmd.Flags().IntVarP(&searchFlags.limit, "limit", "n", 0, "Print at most this many results, 0 prints them all")
+	searchCmd.Aliases = []string{"s", "find"}
+	rootCmd.AddCommand(searchCmd)
+}
+
+// printSearchResults prints a table that fits a terminal, without colors so it can be piped to grep or awk
+func printSearchResults(results []fox.SearchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tVERSION\tINSTALLED\tLANGUAGE\tDESCRIPTION")
+	for _, r := range results {
+		p := r.Package
+		installed := lo.Ternary(len(p.InstalledVersions) > 0, strings.Join(p.InstalledVersions, ","), "-")
+		language := lo.Ternary(p.Language != "", p.Language, "-")
+		description := strings.Join(strings.Fields(p.Description), " ")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.ExecutableName, p.LatestVersion, installed, language, utils.Ellipsis(description, 60))
+	}
+	_ = w.Flush()
+}
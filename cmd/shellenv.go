@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+// shellenvCmd represents the shellenv command
+var shellenvCmd = &cobra.Command{
+	Use:       "shellenv [bash|zsh|fish]",
+	Short:     "Print the exports needed to use the man pages and completions fox installs",
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Long: `
+Print the shell code that adds the man pages and completions installed by fox
+to your MANPATH and your shell's completion paths.
+
+If no shell is given, it is guessed from $SHELL.`,
+	Example: `
+	Add it to your ~/.bashrc:
+	$ eval "$(fox shellenv bash)"
+
+	Add it to your ~/.zshrc, before running compinit:
+	$ eval "$(fox shellenv zsh)"
+
+	Add it to your ~/.config/fish/config.fish:
+	$ fox shellenv fish | source
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			checkErr(fmt.Errorf("I only support one argument. Given: [%s]", strings.Join(args, ", ")), cmd)
+		}
+
+		shell := filepath.Base(os.Getenv("SHELL"))
+		if len(args) == 1 {
+			shell = strings.TrimSpace(args[0])
+		}
+
+		manPath := strings.TrimSuffix(constants.FoxManPath, "/")
+		switch shell {
+		case "bash":
+			fmt.Printf("export MANPATH=\"%s${MANPATH+:$MANPATH}:\";\n", manPath)
+			fmt.Printf("for completion in \"%s\"*; do [ -r \"$completion\" ] && . \"$completion\"; done;\n", constants.FoxBashCompletionsPath)
+		case "zsh":
+			fmt.Printf("export MANPATH=\"%s${MANPATH+:$MANPATH}:\";\n", manPath)
+			fmt.Printf("fpath=(\"%s\" $fpath);\n", strings.TrimSuffix(constants.FoxZshCompletionsPath, "/"))
+		case "fish":
+			fmt.Printf("set -q MANPATH; or set MANPATH '';\n")
+			fmt.Printf("set -gx MANPATH \"%s\" $MANPATH;\n", manPath)
+			fmt.Printf("set -gx fish_complete_path \"%s\" $fish_complete_path;\n", strings.TrimSuffix(constants.FoxFishCompletionsPath, "/"))
+		default:
+			checkErr(fmt.Errorf("error, the shell '"+shell+"' is not supported. Only 'bash', 'zsh' and 'fish' are valid values."), cmd)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellenvCmd)
+}
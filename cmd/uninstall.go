@@ -1,17 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
-	"github.com/ricardofabila/fox/src/constants"
-	"github.com/ricardofabila/fox/src/installations"
-	"github.com/ricardofabila/fox/src/utils"
+	"github.com/ricardofabila/fox/pkg/fox"
 )
 
+type UninstallFlags struct {
+	noHooks bool
+}
+
+var uninstallFlags = UninstallFlags{
+	noHooks: false,
+}
+
 // uninstallCmd yeets packages from your system
 var uninstallCmd = &cobra.Command{
 	Use:   "uninstall",
@@ -29,6 +36,9 @@ var uninstallCmd = &cobra.Command{
 
 	Uninstall a package that was installed with a different name using the --as flag during 'fox install':
 	$ fox uninstall <custom_name>
+
+	Uninstall a package without running its preUninstall hook:
+	$ fox uninstall <package_name> --no-hooks
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
@@ -50,23 +60,17 @@ var uninstallCmd = &cobra.Command{
 		}
 
 		color.Blue("Uninstalling: %s", pkgName)
-		install := installations.FindInstallation(pkgName)
-		if install == nil {
-			utils.CheckErr(fmt.Errorf("Error. No installation found for "+pkgName), cmd)
-			return // add return so that linter stops complaining
-		}
-
-		err := utils.RemoveFile(constants.FoxBinPath + pkgName)
-		if err != nil {
-			utils.CheckErr(err, cmd)
+		installation, err := newClient(true).Uninstall(context.Background(), pkgName, fox.UninstallOptions{NoHooks: uninstallFlags.noHooks})
+		checkErr(err, cmd)
+		if isMachineOutput() {
+			checkErr(printOutput(toInstallationOutput(installation)), cmd)
 		}
-
-		installations.DeleteInstallation(*install)
 		color.Green("Uninstalled: %s", pkgName)
 	},
 }
 
 func init() {
+	uninstallCmd.Flags().BoolVar(&uninstallFlags.noHooks, "no-hooks", false, "Do not run the preUninstall hook of the package")
 	uninstallCmd.Aliases = []string{"yeet", "remove", "rm"}
 	rootCmd.AddCommand(uninstallCmd)
 }
@@ -17,7 +17,6 @@ import (
 
 	"github.com/ricardofabila/fox/src/constants"
 	"github.com/ricardofabila/fox/src/repositories"
-	"github.com/ricardofabila/fox/src/utils"
 )
 
 // updateCmd represents the update command
@@ -55,7 +54,7 @@ in your config file with the option: autoUpdate`,
 		err := repositories.UpdatePackagesCache(repositoriesConfig, true)
 		if err != nil {
 			spin.Stop()
-			utils.CheckErr(err, cmd)
+			checkErr(err, cmd)
 		}
 
 		spin.Stop()
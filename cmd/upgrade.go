@@ -1,22 +1,36 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/ricardofabila/fox/pkg/fox"
 	"github.com/ricardofabila/fox/src/constants"
 	"github.com/ricardofabila/fox/src/installations"
 	"github.com/ricardofabila/fox/src/repositories"
-	"github.com/ricardofabila/fox/src/types"
-	repositories2 "github.com/ricardofabila/fox/src/types/repositories"
 	"github.com/ricardofabila/fox/src/utils"
 )
 
+type UpgradeFlags struct {
+	changelog bool
+	yes       bool
+	dryRun    bool
+}
+
+var upgradeFlags = UpgradeFlags{
+	changelog: false,
+	yes:       false,
+	dryRun:    false,
+}
+
 // upgradeCmd represents the upgrade command
 var upgradeCmd = &cobra.Command{
 	Use:   "upgrade",
@@ -34,11 +48,23 @@ var upgradeCmd = &cobra.Command{
 
 	Upgrade fox:
 	$ fox upgrade fox
+
+	Read the release notes since your version before upgrading:
+	$ fox upgrade <package_name> --changelog
+
+	Upgrade without asking for confirmation, eg: in a script:
+	$ fox upgrade --yes
+
+	See what would be upgraded without upgrading anything:
+	$ fox upgrade --dry-run
+
+	Fail a CI job when something can be upgraded, it exits with 2:
+	$ fox upgrade --dry-run -o json
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		// To upgrade first find the installations FindInstallations
 		// if you find at least one, go fetch the packages. Find the by the original executable name.
-		// get the latest version. Check all the installations, get the one for the executable name. No aliases.
+		// get the latest version. Check all the installations, skipping the pinned ones.
 		// if they differ, go install latest, with the alias or executable name
 		args = lo.Map(args, func(t string, _ int) string {
 			return strings.TrimSpace(t)
@@ -58,13 +84,26 @@ var upgradeCmd = &cobra.Command{
 			color.Blue(" Upgrading fox. You may need to execute this command with 'sudo'")
 			upgradeName := "fox-upgrade"
 			availablePackages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, true)
-			utils.CheckErr(err, cmd)
-			err = installations.InstallPackage(availablePackages, "fox", upgradeName, false, userConfig, true, true)
-			utils.CheckErr(err, cmd)
+			checkErr(err, cmd)
+			result, err := installations.InstallPackage(availablePackages, "fox", installations.InstallOptions{
+				Alias:      upgradeName,
+				UserConfig: userConfig,
+				InstallFox: true,
+				Force:      true,
+			})
+			checkErr(err, cmd)
 			// execute a rename of the downloaded file
 			err = utils.MoveFile(constants.FoxBinPath+upgradeName, constants.FoxBinPath+"fox")
-			utils.CheckErr(err, cmd)
+			checkErr(err, cmd)
 			color.Green(" 🦊 done! You have the latest version of fox.")
+			if isMachineOutput() {
+				checkErr(printOutput([]upgradeOutput{{
+					RealName:       "fox",
+					Package:        result.Installation.Package,
+					CurrentVersion: VERSION,
+					LatestVersion:  result.Installation.Version,
+				}}), cmd)
+			}
 			return
 		}
 
@@ -72,102 +111,112 @@ var upgradeCmd = &cobra.Command{
 			return !strings.EqualFold(t, "fox")
 		})
 
-		installs := installations.LoadInstallations()
-		if len(installs.Installations) == 0 {
-			fmt.Println()
-			color.Blue("You have no installed packages, sir ヾ(_ _。）")
-			fmt.Println()
+		client := newClient(true)
+		installs, err := client.Installed(context.Background())
+		checkErr(err, nil)
+		if len(installs) == 0 && isMachineOutput() {
+			checkErr(printOutput(lo.Ternary[any](upgradeFlags.dryRun, []planEntryOutput{}, []upgradeOutput{})), cmd)
 			return
 		}
 
-		aliasedOrWithVersion := lo.Filter(installs.Installations, func(i types.Installation, _ int) bool {
-			return i.Alias != ""
-		})
-		if len(aliasedOrWithVersion) > 0 {
+		if len(installs) == 0 {
 			fmt.Println()
-			color.Yellow(" Warning! Some of your installations are aliased or have a specific version.")
-			color.Yellow(" Upgrading aliased and versioned installations is not supported.")
-			color.Yellow(" You must upgrade those manually by uninstalling them and reinstalling them.")
-			color.Yellow(" The following have been found:")
-			for _, i := range aliasedOrWithVersion {
-				color.Yellow("      • " + i.RealName)
-			}
+			color.Blue("You have no installed packages, sir ヾ(_ _。）")
 			fmt.Println()
-		}
-
-		// filter out the packages that are already on the latest versions
-		availablePackages, err := repositories.LoadPackagesFromCache(repositoriesConfig, userConfig, true)
-		utils.CheckErr(err, cmd)
-		canBeUpgraded := installations.GetUpgradable(availablePackages, installs)
-
-		if upgradeAll {
-			args = lo.Map(canBeUpgraded, func(p repositories2.Package, _ int) string {
-				return p.ExecutableName
-			})
+			return
 		}
 
 		args = lo.Filter(args, func(n string, _ int) bool {
-			existing := installations.FindInstallation(n)
-			if existing == nil {
+			found := lo.ContainsBy(installs, func(i fox.Installation) bool {
+				return i.RealName == n
+			})
+			if !found {
 				color.Yellow("       Sorry, I couldn't find an installation for: " + n)
 				color.Yellow(" ٩(๏̯๏)۶")
 				fmt.Println()
-				return false
-			}
-
-			// notify that package is already at the latest version
-			pkg := installations.FindPackage(availablePackages, *existing)
-			if pkg != nil {
-				// if !strings.EqualFold(strings.TrimSpace(installation.Version), strings.TrimSpace(pkg.LatestVersion)) {
-				if strings.Contains(strings.TrimSpace(pkg.LatestVersion), strings.TrimSpace(existing.Version)) {
-					color.Blue(" %s is already at the latest version", n)
-					fmt.Println()
-					return false
-				}
 			}
 
-			return true
+			return found
 		})
 
-		// find the installation for the given package
-		var willBeUpgraded []repositories2.Package
-		for _, c := range canBeUpgraded {
-			for _, n := range args {
-				if c.ExecutableName == n {
-					willBeUpgraded = append(willBeUpgraded, c)
-					continue
-				}
+		if !upgradeAll && len(args) == 0 {
+			if isMachineOutput() {
+				checkErr(printOutput(lo.Ternary[any](upgradeFlags.dryRun, []planEntryOutput{}, []upgradeOutput{})), cmd)
 			}
-		}
-
-		// Avoid printing more things, return early
-		if len(willBeUpgraded) == 0 && len(args) == 1 {
 			return
 		}
 
-		if len(willBeUpgraded) == 0 {
-			fmt.Println()
-			color.Blue("You have no installed packages I can update, sir ヾ(_ _。）")
-			fmt.Println()
+		plan, err := client.Plan(context.Background(), args...)
+		checkErr(err, cmd)
+
+		fmt.Println()
+		printPlan(plan)
+
+		if upgradeFlags.dryRun {
+			if isMachineOutput() {
+				checkErr(printOutput(toPlanOutput(plan)), cmd)
+			}
+
+			if len(plan.Upgrades()) > 0 {
+				os.Exit(ExitUpgradesAvailable)
+			}
 			return
 		}
 
-		if len(willBeUpgraded) > 0 {
-			fmt.Println()
-			color.Blue(" The following packages will be upgraded:")
-			fmt.Printf(" %s\n", strings.Join(lo.Map(willBeUpgraded, func(p repositories2.Package, _ int) string {
-				return p.ExecutableName
-			}), ", "))
-			fmt.Println()
-		}
+		upgraded, err := client.Upgrade(context.Background(), fox.UpgradeOptions{
+			Confirm: func(outdated []fox.Outdated) bool {
+				if upgradeFlags.changelog {
+					for _, o := range outdated {
+						releases, found, err := client.Changelog(context.Background(), o)
+						if err != nil {
+							color.Red(" Could not get the releases of %s: %s", o.Installation.RealName, err)
+							fmt.Println()
+							continue
+						}
 
-		// TODO: prompt user for confirmation
+						printChangelog(o, releases, found)
+					}
+				}
 
-		for _, pkg := range willBeUpgraded {
-			color.Green(" Upgrading: " + pkg.ExecutableName)
-			err = installations.InstallPackage(availablePackages, pkg.ExecutableName, "", false, userConfig, false, false)
-			utils.CheckErr(err, cmd)
+				if upgradeFlags.yes {
+					return true
+				}
+
+				prompt := promptui.Select{
+					Label: " Proceed with the upgrade?",
+					Items: []string{"Yes", "No"},
+					// with json or yaml output os.Stdout is stderr, keep the prompt out of the output
+					Stdout: os.Stdout,
+				}
+
+				_, result, err := prompt.Run()
+				// most likely a Control+C, or no terminal to ask in
+				if err != nil || result == "No" {
+					color.Yellow(" Run with --yes to upgrade without confirmation.")
+					return false
+				}
+
+				return true
+			},
+		}, args...)
+		checkErr(err, cmd)
+
+		if isMachineOutput() {
+			checkErr(printOutput(lo.Map(upgraded, func(u fox.UpgradeResult, _ int) upgradeOutput {
+				return upgradeOutput{
+					RealName:       u.Installation.RealName,
+					Package:        u.Installation.Package,
+					CurrentVersion: u.From,
+					LatestVersion:  u.Installation.Version,
+				}
+			})), cmd)
+			return
+		}
+
+		if len(upgraded) == 0 {
+			color.Blue(" Nothing to upgrade ~(‾▿‾)~")
 			fmt.Println()
+			return
 		}
 
 		fmt.Println(" Thank you for participating in this")
@@ -179,4 +228,57 @@ var upgradeCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().BoolVarP(&upgradeFlags.changelog, "changelog", "c", upgradeFlags.changelog, "Show the release notes between your version and the latest one before upgrading")
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.dryRun, "dry-run", upgradeFlags.dryRun, "Print the upgrade plan without upgrading anything, exits with 2 when there is something to upgrade")
+	upgradeCmd.Flags().BoolVarP(&upgradeFlags.yes, "yes", "y", upgradeFlags.yes, "Do not prompt for confirmation before upgrading")
+}
+
+// printPlan prints a table with what upgrading does to each installation
+func printPlan(plan fox.UpgradePlan) {
+	if len(plan) == 0 {
+		color.Blue(" Nothing to upgrade ~(‾▿‾)~")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, " NAME\tCURRENT\tTARGET\tSTATUS\tASSET\tNOTES")
+	var downloadSize int64
+	for _, e := range plan {
+		status := map[string]string{fox.PlanUpgrade: "upgrade", fox.PlanUpToDate: "up to date", fox.PlanHeld: "held"}[e.Status]
+		asset := "-"
+		if e.Asset != nil {
+			asset = fmt.Sprintf("%s (%s)", e.Asset.Name, utils.ByteCountIEC(int64(e.Asset.Size)))
+			downloadSize += int64(e.Asset.Size)
+		} else if e.Status == fox.PlanUpgrade && e.Problem == "" {
+			asset = "source code"
+		}
+
+		var notes []string
+		if e.HeldBecause != "" {
+			notes = append(notes, e.HeldBecause)
+		}
+		if e.Problem != "" {
+			// the problems can span several lines, eg: with a hint to run with --explain
+			notes = append(notes, strings.Split(e.Problem, "\n")[0])
+		}
+		if len(e.MissingDependencies) > 0 {
+			notes = append(notes, "needs "+strings.Join(e.MissingDependencies, ", "))
+		}
+		if len(e.Dependents) > 0 {
+			notes = append(notes, "used by "+strings.Join(e.Dependents, ", "))
+		}
+
+		_, _ = fmt.Fprintf(w, " %s\t%s\t%s\t%s\t%s\t%s\n", e.Installation.RealName, e.Installation.Version, e.Target, status, asset, lo.Ternary(len(notes) == 0, "-", strings.Join(notes, "; ")))
+	}
+	_ = w.Flush()
+	fmt.Println()
+
+	upgrades := len(plan.Upgrades())
+	if upgrades == 0 {
+		color.Blue(" Nothing to upgrade ~(‾▿‾)~")
+	} else {
+		color.Blue(" %d to upgrade, %s to download", upgrades, utils.ByteCountIEC(downloadSize))
+	}
+	fmt.Println()
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/ricardofabila/fox/pkg/fox"
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that your installed packages match what is on disk",
+	Long: `
+Compare your installations against the files in ` + constants.FoxBinPath + `.
+
+It reports:
+  • Missing: installed packages whose executable is gone.
+  • Modified: executables that changed since fox installed them.
+  • Untracked: files fox doesn't know about.
+
+Run 'fox repair' to reinstall missing and modified packages.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			checkErr(fmt.Errorf("'verify' takes no arguments, given: %v", args), cmd)
+		}
+
+		drift, err := newClient(false).Verify(context.Background())
+		checkErr(err, cmd)
+
+		if isMachineOutput() {
+			checkErr(printOutput(driftOutput{
+				Clean:     drift.IsClean(),
+				Missing:   toInstallationsOutput(drift.Missing),
+				Modified:  toInstallationsOutput(drift.Modified),
+				Untracked: lo.Ternary(drift.Untracked == nil, []string{}, drift.Untracked),
+				Unchecked: toInstallationsOutput(drift.Unchecked),
+			}), cmd)
+		} else {
+			fmt.Println()
+			printDrift(drift)
+		}
+
+		if !drift.IsClean() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func printDrift(drift fox.Drift) {
+	if drift.IsClean() {
+		color.Green(" ✅ Everything fox installed is where it should be.")
+	}
+
+	printInstallations := func(title string, installs []fox.Installation) {
+		if len(installs) == 0 {
+			return
+		}
+
+		color.Red(" " + title)
+		for _, i := range installs {
+			fmt.Println("      • " + i.RealName + " (" + i.Package + "@" + i.Version + ")")
+		}
+		fmt.Println()
+	}
+
+	printInstallations("❌ Missing:", drift.Missing)
+	printInstallations("❌ Modified:", drift.Modified)
+
+	if len(drift.Untracked) > 0 {
+		color.Yellow(" 💉 Untracked files in " + constants.FoxBinPath + ":")
+		for _, f := range drift.Untracked {
+			fmt.Println("      • " + f)
+		}
+		fmt.Println()
+	}
+
+	if len(drift.Unchecked) > 0 {
+		color.Blue(" The following were installed before fox recorded checksums, reinstall them to verify them:")
+		for _, i := range drift.Unchecked {
+			fmt.Println("      • " + i.RealName)
+		}
+		fmt.Println()
+	}
+
+	if len(drift.Broken()) > 0 {
+		color.Yellow(" run 'fox repair' to reinstall the broken packages")
+		fmt.Println()
+	}
+}
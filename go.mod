@@ -9,14 +9,17 @@ require (
 	github.com/gdamore/tcell/v2 v2.5.3
 	github.com/goccy/go-yaml v1.9.5
 	github.com/ivanpirog/coloredcobra v1.0.1
+	github.com/klauspost/compress v1.15.15
 	github.com/lithammer/fuzzysearch v1.1.5
 	github.com/manifoldco/promptui v0.9.0
 	github.com/mattn/go-colorable v0.1.13
+	github.com/mattn/go-isatty v0.0.16
 	github.com/ricardofabila/build v1.0.2
 	github.com/rivo/tview v0.0.0-20220903125348-532bb46474ec
 	github.com/samber/lo v1.28.0
 	github.com/spf13/cobra v1.5.0
 	github.com/spf13/viper v1.13.0
+	github.com/ulikunitz/xz v0.5.11
 	golang.org/x/sys v0.0.0-20220913175220-63ea55921009
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -30,7 +33,6 @@ require (
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/magiconair/properties v1.8.6 // indirect
-	github.com/mattn/go-isatty v0.0.16 // indirect
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/pelletier/go-toml v1.9.5 // indirect
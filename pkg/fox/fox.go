@@ -0,0 +1,492 @@
+// Package fox installs packages from GitHub releases, the same way the fox command does.
+//
+// Every client has its own root path, network and output, but fox keeps them in package variables while it works.
+// So clients are serialized: a call waits until the call of any other client of the program has finished, and
+// while it runs, code using the internal packages of fox directly sees the root path, network and output of
+// that client. Use one client per root path and don't expect two of them to work in parallel.
+//
+//	client, err := fox.New(fox.Options{RootPath: "/opt/tools"})
+//	result, err := client.Install(ctx, "tool@v1.2.0", fox.InstallOptions{})
+package fox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/installations"
+	"github.com/ricardofabila/fox/src/repositories"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// The classes of the errors the client returns, check them with errors.Is
+var (
+	ErrNetwork         = utils.ErrNetwork
+	ErrAuth            = utils.ErrAuth
+	ErrNotFound        = utils.ErrNotFound
+	ErrStateCorruption = utils.ErrStateCorruption
+	ErrAborted         = utils.ErrAborted
+)
+
+// Options configure a Client. The zero value works like the fox command with an empty config.
+type Options struct {
+	// RootPath is where packages are installed, executables go in RootPath/bin/. /usr/local/Fox/ when empty
+	RootPath string
+	// Remotes and Packages are where packages come from on top of the global remote, like ~/.fox/repositories.yaml
+	Remotes  []Remote
+	Packages []ConfigPackage
+	// Config is the same as ~/.fox/config.yaml, its network settings are not used when HTTPClient is set
+	Config Config
+	// HTTPClient sends the requests of fox, its Transport and Timeout are used
+	HTTPClient *http.Client
+	// Output receives the messages of fox, io.Discard silences them. The output of the program, os.Stdout, when nil
+	Output io.Writer
+	// Interactive asks the user before doing things, eg: running the hooks of a package
+	Interactive bool
+}
+
+// Client installs and upgrades packages. Its methods, and the ones of every other client, run one at a time.
+type Client struct {
+	options Options
+	// root and network are the ones of the options, see use
+	root    string
+	network utils.Network
+	// packages are the available packages, once they have been refreshed
+	packages []repositoriesTypes.Package
+}
+
+// state is held by the client that is using the paths, network and output of fox, see Client.use
+var state sync.Mutex
+
+// InstallOptions are the ways a package can be installed
+type InstallOptions struct {
+	// Alias installs the package with another executable name
+	Alias string
+	// Force installs the package even if it is already at the latest version
+	Force bool
+	// Explain prints the ranked assets of the release and why each one was, or wasn't, chosen
+	Explain bool
+	// NoHooks skips the hooks of the package
+	NoHooks bool
+	// Env and Args make the executable always run with them, through a wrapper.
+	// They win over the ones of the package and the previous installation, an empty variable
+	// removes it and an empty argument removes them all
+	Env  map[string]string
+	Args []string
+}
+
+// Outdated is an installation with a newer version available
+type Outdated struct {
+	Installation Installation
+	Package      Package
+}
+
+// UpgradeOptions are the ways packages can be upgraded
+type UpgradeOptions struct {
+	// Confirm is called with the installations about to be upgraded, returning false upgrades none of them
+	Confirm func(outdated []Outdated) bool
+}
+
+// UpgradeResult is an installation that was upgraded
+type UpgradeResult struct {
+	// From is the version that was installed before
+	From         string
+	Installation Installation
+}
+
+// PackageInfo is a package and its installations
+type PackageInfo struct {
+	Package       Package
+	Installations []Installation
+}
+
+// New returns a client with the options, nothing changes for the rest of the program
+func New(options Options) (*Client, error) {
+	c := &Client{options: options, root: lo.Ternary(options.RootPath != "", options.RootPath, constants.FoxRootPath)}
+	if options.HTTPClient != nil {
+		c.network = utils.NetworkOf(options.HTTPClient)
+	} else {
+		network, err := utils.NewNetwork(options.Config.userConfig())
+		if err != nil {
+			return nil, err
+		}
+		c.network = network
+	}
+
+	if options.RootPath != "" {
+		defer c.use()()
+		err := utils.CreateDirectoryIfNotExists(constants.FoxBinPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// use makes fox work with the root path, network and output of the client, until the returned function is called.
+// Public methods call it once, and only call unexported ones, it can't be held twice.
+func (c *Client) use() func() {
+	state.Lock()
+	root, network := constants.FoxRootPath, utils.UseNetwork(c.network)
+	output, progressOutput := color.Output, utils.ProgressOutput
+	constants.SetRootPath(c.root)
+	if c.options.Output != nil {
+		color.Output, utils.ProgressOutput = c.options.Output, c.options.Output
+	}
+
+	return func() {
+		constants.SetRootPath(root)
+		utils.UseNetwork(network)
+		color.Output, utils.ProgressOutput = output, progressOutput
+		state.Unlock()
+	}
+}
+
+// loadPackages returns the available packages. Refreshing them takes a while, so it only happens once per client.
+// Their installed versions are always the current ones, the cache only has the ones of the last update.
+func (c *Client) loadPackages(ctx context.Context, refresh bool) ([]repositoriesTypes.Package, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	packages := c.packages
+	if packages == nil {
+		var err error
+		packages, err = repositories.LoadPackagesFromCache(c.options.repositoriesConfig(), c.options.Config.userConfig(), refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		if refresh {
+			c.packages = packages
+		}
+	}
+
+	installs, err := installations.LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
+	repositories.SetInstalledVersions(packages, installs.Installations)
+	return packages, nil
+}
+
+// Update refreshes the list of available packages from the remotes and GitHub
+func (c *Client) Update(ctx context.Context) error {
+	defer c.use()()
+	c.packages = nil
+	_, err := c.loadPackages(ctx, true)
+	return err
+}
+
+// Install installs a package, name can have a version, eg: tool@v1.2.0
+func (c *Client) Install(ctx context.Context, name string, options InstallOptions) (InstallResult, error) {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	result, err := installations.InstallPackage(packages, name, installations.InstallOptions{
+		Alias:       options.Alias,
+		Interactive: c.options.Interactive,
+		UserConfig:  c.options.Config.userConfig(),
+		Force:       options.Force,
+		Explain:     options.Explain,
+		NoHooks:     options.NoHooks,
+		Env:         options.Env,
+		Args:        options.Args,
+		Context:     ctx,
+	})
+	return newInstallResult(result), err
+}
+
+// Uninstall removes an installation by its executable name, or alias, and returns it
+func (c *Client) Uninstall(ctx context.Context, name string, options UninstallOptions) (Installation, error) {
+	if err := ctx.Err(); err != nil {
+		return Installation{}, err
+	}
+
+	defer c.use()()
+	installation, err := installations.UninstallPackage(strings.TrimSpace(name), installations.UninstallOptions(options))
+	return newInstallation(installation), err
+}
+
+// Pin makes upgrades skip the installation with the given executable name or alias
+func (c *Client) Pin(ctx context.Context, name string) (Installation, error) {
+	if err := ctx.Err(); err != nil {
+		return Installation{}, err
+	}
+
+	defer c.use()()
+	installation, err := installations.SetPinned(strings.TrimSpace(name), true)
+	return newInstallation(installation), err
+}
+
+// Unpin lets upgrades upgrade the installation with the given executable name or alias again
+func (c *Client) Unpin(ctx context.Context, name string) (Installation, error) {
+	if err := ctx.Err(); err != nil {
+		return Installation{}, err
+	}
+
+	defer c.use()()
+	installation, err := installations.SetPinned(strings.TrimSpace(name), false)
+	return newInstallation(installation), err
+}
+
+// Outdated returns the installations with a newer version, of the given executable names or all of them.
+// Pinned installations are not upgradable.
+func (c *Client) Outdated(ctx context.Context, names ...string) ([]Outdated, error) {
+	defer c.use()()
+	return c.outdated(ctx, false, names)
+}
+
+func (c *Client) outdated(ctx context.Context, refresh bool, names []string) ([]Outdated, error) {
+	packages, err := c.loadPackages(ctx, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	installs, err := installations.LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !lo.ContainsBy(installs.Installations, func(i types.Installation) bool { return i.RealName == name }) {
+			return nil, utils.Classify(ErrNotFound, fmt.Errorf("Error. No installation found for "+name))
+		}
+	}
+
+	var outdated []Outdated
+	for _, pkg := range installations.GetUpgradable(packages, installs) {
+		for _, installation := range installs.Installations {
+			if installation.Package != pkg.NameWithOwner || installation.IsPinned() {
+				continue
+			}
+
+			if len(names) > 0 && !lo.Contains(names, installation.RealName) {
+				continue
+			}
+
+			outdated = append(outdated, Outdated{Installation: newInstallation(installation), Package: newPackage(pkg)})
+		}
+	}
+
+	return lo.UniqBy(outdated, func(o Outdated) string {
+		return o.Installation.RealName
+	}), nil
+}
+
+// Upgrade installs the latest version of the given executable names, or of every outdated installation.
+// It returns the upgraded installations, even when it fails halfway.
+func (c *Client) Upgrade(ctx context.Context, options UpgradeOptions, names ...string) ([]UpgradeResult, error) {
+	done := c.use()
+	outdated, err := c.outdated(ctx, true, names)
+	done()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outdated) == 0 {
+		return nil, nil
+	}
+
+	// without using the client, Confirm can call it
+	if options.Confirm != nil && !options.Confirm(outdated) {
+		return nil, utils.Classify(ErrAborted, fmt.Errorf("Aborting upgrade."))
+	}
+
+	defer c.use()()
+	var upgraded []UpgradeResult
+	for _, o := range outdated {
+		color.Green(" Upgrading: " + o.Installation.RealName)
+		// aliased installations are upgraded in place, keeping their name
+		result, err := installations.InstallPackage(c.packages, o.Installation.ExecutableName, installations.InstallOptions{
+			Alias:      o.Installation.Alias,
+			UserConfig: c.options.Config.userConfig(),
+			Context:    ctx,
+		})
+		if err != nil {
+			return upgraded, err
+		}
+
+		upgraded = append(upgraded, UpgradeResult{From: o.Installation.Version, Installation: newInstallation(result.Installation)})
+	}
+
+	return upgraded, nil
+}
+
+// List returns the packages that can be installed
+func (c *Client) List(ctx context.Context) ([]Package, error) {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPackages(lo.Filter(packages, func(p repositoriesTypes.Package, _ int) bool {
+		return p.IsVisible()
+	})), nil
+}
+
+// Search returns the packages that match the query in their executable name, repository, description or language,
+// best matches first
+func (c *Client) Search(ctx context.Context, query string, filters SearchFilters) ([]SearchResult, error) {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSearchResults(repositories.SearchPackages(packages, query, repositories.SearchFilters(filters))), nil
+}
+
+// Releases returns the published releases of the package with the given executable name, newest first.
+// Prereleases are included, see Release.Prerelease
+func (c *Client) Releases(ctx context.Context, name string) ([]Release, error) {
+	defer c.use()()
+	pkg, err := c.findPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := pkg.GetReleases(ctx)
+	return newReleases(releases), err
+}
+
+// Changelog returns the releases between the installed version of an outdated installation and the latest one,
+// newest first. found is false when the installed version is not among the releases, then all of them are returned.
+func (c *Client) Changelog(ctx context.Context, outdated Outdated) (releases []Release, found bool, err error) {
+	defer c.use()()
+	pkg := outdated.Package.internalPackage()
+	all, err := pkg.GetReleases(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	since, found := installations.ReleasesSince(all, outdated.Installation.Version)
+	return newReleases(since), found, nil
+}
+
+// Installed returns the installed packages
+func (c *Client) Installed(ctx context.Context) ([]Installation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	defer c.use()()
+	installs, err := installations.LoadInstallations()
+	return newInstallations(installs.Installations), err
+}
+
+// Info returns the package with the given executable name and its installations
+func (c *Client) Info(ctx context.Context, name string) (PackageInfo, error) {
+	defer c.use()()
+	pkg, err := c.findPackage(ctx, name)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+
+	installs, err := installations.FindInstallations(pkg.ExecutableName)
+	return PackageInfo{Package: newPackage(pkg), Installations: newInstallations(installs)}, err
+}
+
+// RunOptions are the ways a package can be run
+type RunOptions struct {
+	// Explain prints the ranked assets of the release and why each one was, or wasn't, chosen
+	Explain bool
+}
+
+// Run runs the package, name can be tool@v1.0.0, without installing it. The executable is kept in the downloads cache.
+// The program is replaced by the package, Run only returns if it couldn't run it.
+func (c *Client) Run(ctx context.Context, name string, args []string, options RunOptions) error {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	return installations.RunPackage(packages, name, args, installations.InstallOptions{
+		UserConfig: c.options.Config.userConfig(),
+		Explain:    options.Explain,
+		Context:    ctx,
+	})
+}
+
+// Repair reinstalls the recorded version of an installation that Verify reports as missing or modified,
+// keeping its name. If it fails, the file and record of the installation are left as they were.
+func (c *Client) Repair(ctx context.Context, realName string) (Installation, error) {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	installs, err := installations.LoadInstallations()
+	if err != nil {
+		return Installation{}, err
+	}
+
+	installation, found := lo.Find(installs.Installations, func(i types.Installation) bool {
+		return i.RealName == realName
+	})
+	if !found {
+		return Installation{}, utils.Classify(ErrNotFound, fmt.Errorf("Error. No installation found for "+realName))
+	}
+
+	err = installations.RepairInstallation(packages, installation, c.options.Config.userConfig())
+	return newInstallation(installation), err
+}
+
+// Verify compares the installations against the files on disk, without changing anything
+func (c *Client) Verify(ctx context.Context) (Drift, error) {
+	if err := ctx.Err(); err != nil {
+		return Drift{}, err
+	}
+
+	defer c.use()()
+	drift, err := installations.VerifyInstallations()
+	return newDrift(drift), err
+}
+
+func (c *Client) findPackage(ctx context.Context, name string) (repositoriesTypes.Package, error) {
+	packages, err := c.loadPackages(ctx, false)
+	if err != nil {
+		return repositoriesTypes.Package{}, err
+	}
+
+	pkg, found := lo.Find(packages, func(p repositoriesTypes.Package) bool {
+		return strings.EqualFold(p.ExecutableName, strings.TrimSpace(name))
+	})
+	if !found {
+		return pkg, utils.Classify(ErrNotFound, fmt.Errorf("Could not find the package '%s'. Try running 'fox update' first.", name))
+	}
+
+	return pkg, nil
+}
+
+// HasBreakingChanges tells if the notes of any of the releases, eg: the ones of Changelog, announce a breaking change
+func HasBreakingChanges(releases []Release) bool {
+	return installations.HasBreakingChanges(lo.Map(releases, func(r Release, _ int) repositoriesTypes.Release {
+		return r.internalRelease()
+	}))
+}
+
+// CompatibleAssets returns the assets of the release that can run on this system, the one Install picks first
+func CompatibleAssets(pkg Package, release Release) []Asset {
+	candidates := installations.CompatibleAssets(release.internalRelease(), pkg.internalPackage(), installations.CurrentHost())
+	return lo.Map(candidates, func(c installations.AssetCandidate, _ int) Asset {
+		return newAsset(c.Asset)
+	})
+}
@@ -0,0 +1,161 @@
+package fox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/installations"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// The statuses of an installation in an upgrade plan
+const (
+	PlanUpgrade  = "upgrade"
+	PlanUpToDate = "upToDate"
+	PlanHeld     = "held"
+)
+
+// PlanEntry is what upgrading does to an installation
+type PlanEntry struct {
+	Installation Installation
+	// Package is nil when the package is no longer in the repositories
+	Package *Package
+	Status  string
+	// Target is the version the installation is upgraded to, the installed one when it isn't upgraded
+	Target string
+	// Asset is the asset that will be downloaded, nil for scripts installed from the source code of the release
+	Asset *Asset
+	// HeldBecause is why a held installation is not upgraded
+	HeldBecause string
+	// Problem is why the upgrade will fail, eg: the new version has no asset for this system
+	Problem string
+	// MissingDependencies are the executables the package depends on that are not installed
+	MissingDependencies []string
+	// Dependents are the installations of packages that depend on this one
+	Dependents []string
+}
+
+// UpgradePlan is what upgrading the installations does, one entry per installation
+type UpgradePlan []PlanEntry
+
+// Upgrades are the entries of the installations that will be upgraded
+func (p UpgradePlan) Upgrades() []PlanEntry {
+	return lo.Filter(p, func(e PlanEntry, _ int) bool {
+		return e.Status == PlanUpgrade
+	})
+}
+
+// Plan works out what Upgrade does to the installations with the given executable names, or to every installation,
+// without changing anything. It fetches the latest versions and the releases of the outdated packages.
+func (c *Client) Plan(ctx context.Context, names ...string) (UpgradePlan, error) {
+	defer c.use()()
+	packages, err := c.loadPackages(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	installs, err := installations.LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !lo.ContainsBy(installs.Installations, func(i types.Installation) bool { return i.RealName == name }) {
+			return nil, utils.Classify(ErrNotFound, fmt.Errorf("Error. No installation found for "+name))
+		}
+	}
+
+	plan := UpgradePlan{}
+	for _, installation := range installs.Installations {
+		if len(names) > 0 && !lo.Contains(names, installation.RealName) {
+			continue
+		}
+
+		entry, err := planEntry(ctx, packages, installs.Installations, installation)
+		if err != nil {
+			return nil, err
+		}
+
+		plan = append(plan, entry)
+	}
+
+	return plan, nil
+}
+
+func planEntry(ctx context.Context, packages []repositoriesTypes.Package, installs []types.Installation, installation types.Installation) (PlanEntry, error) {
+	entry := PlanEntry{Installation: newInstallation(installation), Status: PlanUpToDate, Target: installation.Version}
+	pkg, found := lo.Find(packages, func(p repositoriesTypes.Package) bool {
+		return p.NameWithOwner == installation.Package
+	})
+	if !found {
+		entry.Status = PlanHeld
+		entry.HeldBecause = "the package is no longer in the repositories"
+		return entry, nil
+	}
+
+	entry.Package = lo.ToPtr(newPackage(pkg))
+	entry.Dependents = dependents(packages, installs, pkg.ExecutableName)
+	entry.MissingDependencies = lo.Filter(pkg.DependsOn, func(d string, _ int) bool {
+		installed := lo.ContainsBy(installs, func(i types.Installation) bool { return i.RealName == d })
+		return !installed && utils.IsOnPath(d) == ""
+	})
+
+	// the same comparison as installations.GetUpgradable
+	if strings.Contains(strings.TrimSpace(pkg.LatestVersion), strings.TrimSpace(installation.Version)) {
+		return entry, nil
+	}
+
+	if installation.IsPinned() {
+		entry.Status = PlanHeld
+		entry.HeldBecause = "pinned"
+		return entry, nil
+	}
+
+	entry.Status = PlanUpgrade
+	entry.Target = pkg.LatestVersion
+
+	releases, err := pkg.GetReleases(ctx)
+	if err != nil {
+		return entry, err
+	}
+
+	release := installations.FindRelease(releases, "latest")
+	if release == nil {
+		entry.Problem = "the package has no releases"
+		return entry, nil
+	}
+
+	entry.Target = release.Tag
+	asset, err := installations.AssetFor(pkg, *release)
+	if errors.Is(err, ErrNotFound) {
+		entry.Problem = err.Error()
+		return entry, nil
+	}
+
+	if asset != nil {
+		entry.Asset = lo.ToPtr(newAsset(*asset))
+	}
+
+	return entry, err
+}
+
+// dependents returns the real names of the installations whose package depends on the executable
+func dependents(packages []repositoriesTypes.Package, installs []types.Installation, executableName string) []string {
+	var names []string
+	for _, i := range installs {
+		pkg, found := lo.Find(packages, func(p repositoriesTypes.Package) bool {
+			return p.NameWithOwner == i.Package
+		})
+		if found && lo.Contains(pkg.DependsOn, executableName) {
+			names = append(names, i.RealName)
+		}
+	}
+
+	return names
+}
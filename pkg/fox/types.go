@@ -0,0 +1,434 @@
+package fox
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/installations"
+	"github.com/ricardofabila/fox/src/repositories"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// Package is a package that can be installed
+type Package struct {
+	// ExecutableName is the name of the main executable, packages are installed by it
+	ExecutableName string
+	// Name and NameWithOwner are the GitHub repository, eg: cli and cli/cli
+	Name          string
+	NameWithOwner string
+	Description   string
+	URL           string
+	// Language is the main language of the repository, eg: Go
+	Language string
+	// UpdatedAt is when the repository was last updated, as GitHub sends it
+	UpdatedAt     string
+	Type          string
+	LatestVersion string
+	// DependsOn are the executables the package needs, fox doesn't install them
+	DependsOn []string
+	// Executables are the extra executables installed along with the main one
+	Executables []Executable
+	// CompletionsCommand generates completions when run as: <executable> <completionsCommand> <shell>
+	CompletionsCommand string
+	// Assets maps os/arch to a glob or 'regex:' template of the asset to download
+	Assets map[string]string
+	// BinaryPath is the path of the executable inside the archive
+	BinaryPath string
+	// Test is a command that checks the installed executable works, eg: tool --version
+	Test  string
+	Hooks Hooks
+	// Env and Args are the environment variables and arguments the executable runs with by default
+	Env  map[string]string
+	Args []string
+	// InstalledVersions and Aliases are the ones of the installations of the package
+	InstalledVersions []string
+	Aliases           []string
+	// Conflicts is the executable on the PATH with the same name, when the package is not installed
+	Conflicts string
+}
+
+// Executable is an extra executable shipped in the same release archive as the main one
+type Executable struct {
+	Name string
+	// As is the optional name to install the executable as
+	As string
+}
+
+// InstallName is the name the executable is installed as
+func (e Executable) InstallName() string {
+	return lo.Ternary(strings.TrimSpace(e.As) != "", strings.TrimSpace(e.As), strings.TrimSpace(e.Name))
+}
+
+// Hooks are shell commands run around the installation, the user approves them the first time
+type Hooks struct {
+	PreInstall   string
+	PostInstall  string
+	PreUninstall string
+}
+
+// Release is a GitHub release of a package
+type Release struct {
+	Tag  string
+	Name string
+	// Prerelease releases are never installed as the latest version, only by their tag
+	Prerelease bool
+	// Body is the release notes, in markdown
+	Body        string
+	CreatedAt   string
+	PublishedAt string
+	Assets      []Asset
+}
+
+// Asset is a file of a release
+type Asset struct {
+	Name string
+	// Size is in bytes
+	Size int
+	URL  string
+}
+
+// Installation is an installed package
+type Installation struct {
+	// Package is the GitHub repository of the package, eg: cli/cli
+	Package        string
+	ExecutableName string
+	// RealName is the name of the executable in the bin folder, the alias or the executable name
+	RealName string
+	Alias    string
+	Version  string
+	// Timestamp is when it was installed, in unix milliseconds
+	Timestamp int64
+	// SHA256 and Size are of the executable at install time, of the wrapper when there is one
+	SHA256 string
+	Size   int64
+	// Executables are the extra executables installed along with the main one
+	Executables []InstalledExecutable
+	// ShareFiles are the paths of the man pages and completions installed with the package
+	ShareFiles []string
+	// Env and Args are what the executable runs with, through a wrapper
+	Env  map[string]string
+	Args []string
+	// StorePath is where the real executable is when the installation runs through a wrapper,
+	// StoreSHA256 and StoreSize are of it at install time
+	StorePath   string
+	StoreSHA256 string
+	StoreSize   int64
+	// Pinned installations are skipped by Upgrade
+	Pinned bool
+}
+
+// InstalledExecutable is an extra executable of an installation
+type InstalledExecutable struct {
+	Name   string
+	SHA256 string
+	Size   int64
+}
+
+// IsWrapped tells if the executable runs through a wrapper that sets its environment and arguments
+func (i Installation) IsWrapped() bool {
+	return i.StorePath != ""
+}
+
+// IsPinned tells if upgrades skip the installation. Installations named after their version, eg: tool@v1.0.0, always are
+func (i Installation) IsPinned() bool {
+	return i.Pinned || strings.Contains(i.RealName, "@")
+}
+
+// Remote is a list of packages, see 'fox remote --help'
+type Remote struct {
+	URL  string
+	Type string
+}
+
+// ConfigPackage is a package from a GitHub repository, see 'fox add --help'
+type ConfigPackage struct {
+	// Path is the GitHub repository, eg: cli/cli
+	Path               string
+	ExecutableName     string
+	Type               string
+	DependsOn          []string
+	Executables        []Executable
+	CompletionsCommand string
+	Assets             map[string]string
+	BinaryPath         string
+	Test               string
+	Hooks              Hooks
+	Env                map[string]string
+	Args               []string
+}
+
+// Config is the same as ~/.fox/config.yaml
+type Config struct {
+	// AutoUpdate refreshes the available packages every time they are loaded
+	AutoUpdate             bool
+	NotifyOutdatedVersions bool
+	InstallManPages        bool
+	InstallCompletions     bool
+	// DownloadCacheSizeMB limits the downloads cache, 0 uses the default and a negative value disables it
+	DownloadCacheSizeMB int64
+	// HTTPTimeoutSeconds is how long a request can wait for the server, 0 uses the default
+	HTTPTimeoutSeconds int64
+	Network            NetworkConfig
+	// Hosts overrides the network settings for some hosts, eg: an internal artifacts server
+	Hosts map[string]NetworkConfig
+}
+
+// NetworkConfig is how fox connects to servers
+type NetworkConfig struct {
+	// Proxy is the URL of the proxy to use, HTTPS_PROXY and NO_PROXY are used when empty.
+	// In Hosts, "direct" connects without a proxy.
+	Proxy string
+	// CABundle is a PEM file with certificates to trust on top of the system ones
+	CABundle string
+	// ClientCert and ClientKey are PEM files to authenticate to servers that ask for a certificate
+	ClientCert string
+	ClientKey  string
+}
+
+// InstallResult is what Install did
+type InstallResult struct {
+	Installation Installation
+	// UpToDate is true when the package was already installed at the latest version, and nothing changed
+	UpToDate bool
+}
+
+// UninstallOptions are the ways a package can be uninstalled
+type UninstallOptions struct {
+	// NoHooks skips the preUninstall hook of the package
+	NoHooks bool
+}
+
+// SearchFilters narrow down the packages Search returns
+type SearchFilters struct {
+	Installed bool
+	Language  string
+	// Owner is the user or organization of the repository, eg: cli in cli/cli
+	Owner string
+}
+
+// SearchResult is a package that matched a search, the higher the score the better
+type SearchResult struct {
+	Package Package
+	Score   int
+}
+
+// Drift is how the installations differ from the files on disk
+type Drift struct {
+	// Missing installations have a record but no file
+	Missing []Installation
+	// Modified installations have a file whose checksum differs from the one recorded at install time
+	Modified []Installation
+	// Unchecked installations were made before checksums were recorded, so they can't be verified
+	Unchecked []Installation
+	// Untracked are files in the bin folder that no installation knows about
+	Untracked []string
+}
+
+// IsClean tells if every installation matches its file, unchecked ones aside
+func (d Drift) IsClean() bool {
+	return len(d.Missing) == 0 && len(d.Modified) == 0 && len(d.Untracked) == 0
+}
+
+// Broken returns the installations that can be fixed by reinstalling them
+func (d Drift) Broken() []Installation {
+	return append(append([]Installation{}, d.Missing...), d.Modified...)
+}
+
+func newPackage(p repositoriesTypes.Package) Package {
+	return Package{
+		ExecutableName:     p.ExecutableName,
+		Name:               p.Name,
+		NameWithOwner:      p.NameWithOwner,
+		Description:        p.Description,
+		URL:                p.URL,
+		Language:           p.PrimaryLanguage["name"],
+		UpdatedAt:          p.UpdatedAt,
+		Type:               p.Type,
+		LatestVersion:      p.LatestVersion,
+		DependsOn:          p.DependsOn,
+		Executables:        newExecutables(p.Executables),
+		CompletionsCommand: p.CompletionsCommand,
+		Assets:             p.Assets,
+		BinaryPath:         p.BinaryPath,
+		Test:               p.Test,
+		Hooks:              Hooks(p.Hooks),
+		Env:                p.Env,
+		Args:               p.Args,
+		InstalledVersions:  p.InstalledVersions,
+		Aliases:            p.Aliases,
+		Conflicts:          p.Conflicts,
+	}
+}
+
+func newPackages(packages []repositoriesTypes.Package) []Package {
+	return lo.Map(packages, func(p repositoriesTypes.Package, _ int) Package {
+		return newPackage(p)
+	})
+}
+
+// internalPackage is the package as fox works with it
+func (p Package) internalPackage() repositoriesTypes.Package {
+	return repositoriesTypes.Package{
+		ExecutableName:     p.ExecutableName,
+		Name:               p.Name,
+		NameWithOwner:      p.NameWithOwner,
+		Description:        p.Description,
+		URL:                p.URL,
+		PrimaryLanguage:    map[string]string{"name": p.Language},
+		UpdatedAt:          p.UpdatedAt,
+		Type:               p.Type,
+		LatestVersion:      p.LatestVersion,
+		DependsOn:          p.DependsOn,
+		Executables:        internalExecutables(p.Executables),
+		CompletionsCommand: p.CompletionsCommand,
+		Assets:             p.Assets,
+		BinaryPath:         p.BinaryPath,
+		Test:               p.Test,
+		Hooks:              repositoriesTypes.Hooks(p.Hooks),
+		Env:                p.Env,
+		Args:               p.Args,
+		InstalledVersions:  p.InstalledVersions,
+		Aliases:            p.Aliases,
+		Conflicts:          p.Conflicts,
+	}
+}
+
+func newExecutables(executables []repositoriesTypes.Executable) []Executable {
+	return lo.Map(executables, func(e repositoriesTypes.Executable, _ int) Executable {
+		return Executable(e)
+	})
+}
+
+func internalExecutables(executables []Executable) []repositoriesTypes.Executable {
+	return lo.Map(executables, func(e Executable, _ int) repositoriesTypes.Executable {
+		return repositoriesTypes.Executable(e)
+	})
+}
+
+func newRelease(r repositoriesTypes.Release) Release {
+	return Release{
+		Tag:         r.Tag,
+		Name:        r.Name,
+		Prerelease:  r.Prerelease,
+		Body:        r.Body,
+		CreatedAt:   r.CreatedAt,
+		PublishedAt: r.PublishedAt,
+		Assets: lo.Map(r.Assets, func(a repositoriesTypes.Asset, _ int) Asset {
+			return newAsset(a)
+		}),
+	}
+}
+
+func newReleases(releases []repositoriesTypes.Release) []Release {
+	return lo.Map(releases, func(r repositoriesTypes.Release, _ int) Release {
+		return newRelease(r)
+	})
+}
+
+func (r Release) internalRelease() repositoriesTypes.Release {
+	return repositoriesTypes.Release{
+		Tag:         r.Tag,
+		Name:        r.Name,
+		Prerelease:  r.Prerelease,
+		Body:        r.Body,
+		CreatedAt:   r.CreatedAt,
+		PublishedAt: r.PublishedAt,
+		Assets: lo.Map(r.Assets, func(a Asset, _ int) repositoriesTypes.Asset {
+			return repositoriesTypes.Asset{Name: a.Name, Tag: r.Tag, Size: a.Size, BrowserDownloadURL: a.URL}
+		}),
+	}
+}
+
+func newAsset(a repositoriesTypes.Asset) Asset {
+	return Asset{Name: a.Name, Size: a.Size, URL: a.BrowserDownloadURL}
+}
+
+func newInstallation(i types.Installation) Installation {
+	return Installation{
+		Package:        i.Package,
+		ExecutableName: i.ExecutableName,
+		RealName:       i.RealName,
+		Alias:          i.Alias,
+		Version:        i.Version,
+		Timestamp:      i.Timestamp,
+		SHA256:         i.SHA256,
+		Size:           i.Size,
+		Executables: lo.Map(i.Executables, func(e types.InstalledExecutable, _ int) InstalledExecutable {
+			return InstalledExecutable(e)
+		}),
+		ShareFiles:  i.ShareFiles,
+		Env:         i.Env,
+		Args:        i.Args,
+		StorePath:   i.StorePath,
+		StoreSHA256: i.StoreSHA256,
+		StoreSize:   i.StoreSize,
+		Pinned:      i.Pinned,
+	}
+}
+
+func newInstallations(installs []types.Installation) []Installation {
+	return lo.Map(installs, func(i types.Installation, _ int) Installation {
+		return newInstallation(i)
+	})
+}
+
+func newDrift(d installations.Drift) Drift {
+	return Drift{
+		Missing:   newInstallations(d.Missing),
+		Modified:  newInstallations(d.Modified),
+		Unchecked: newInstallations(d.Unchecked),
+		Untracked: d.Untracked,
+	}
+}
+
+func newInstallResult(result installations.InstallResult) InstallResult {
+	return InstallResult{Installation: newInstallation(result.Installation), UpToDate: result.UpToDate}
+}
+
+func newSearchResults(results []repositories.SearchResult) []SearchResult {
+	return lo.Map(results, func(r repositories.SearchResult, _ int) SearchResult {
+		return SearchResult{Package: newPackage(r.Package), Score: r.Score}
+	})
+}
+
+// userConfig is the config as fox works with it
+func (c Config) userConfig() types.UserConfig {
+	return types.UserConfig{
+		AutoUpdate:             c.AutoUpdate,
+		NotifyOutdatedVersions: c.NotifyOutdatedVersions,
+		InstallManPages:        c.InstallManPages,
+		InstallCompletions:     c.InstallCompletions,
+		DownloadCacheSizeMB:    c.DownloadCacheSizeMB,
+		HTTPTimeoutSeconds:     c.HTTPTimeoutSeconds,
+		NetworkConfig:          types.NetworkConfig(c.Network),
+		Hosts: lo.MapValues(c.Hosts, func(n NetworkConfig, _ string) types.NetworkConfig {
+			return types.NetworkConfig(n)
+		}),
+	}
+}
+
+func (o Options) repositoriesConfig() repositoriesTypes.Config {
+	return repositoriesTypes.Config{
+		Remotes: lo.Map(o.Remotes, func(r Remote, _ int) repositoriesTypes.Remote {
+			return repositoriesTypes.Remote(r)
+		}),
+		Packages: lo.Map(o.Packages, func(p ConfigPackage, _ int) repositoriesTypes.ConfigPackage {
+			return repositoriesTypes.ConfigPackage{
+				Path:               p.Path,
+				ExecutableName:     p.ExecutableName,
+				Type:               p.Type,
+				DependsOn:          p.DependsOn,
+				Executables:        internalExecutables(p.Executables),
+				CompletionsCommand: p.CompletionsCommand,
+				Assets:             p.Assets,
+				BinaryPath:         p.BinaryPath,
+				Test:               p.Test,
+				Hooks:              repositoriesTypes.Hooks(p.Hooks),
+				Env:                p.Env,
+				Args:               p.Args,
+			}
+		}),
+	}
+}
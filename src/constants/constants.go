@@ -1,20 +1,66 @@
 package constants
 
-import "runtime"
+import "strings"
 
 const GlobalRemote = "https://raw.githubusercontent.com/ricardofabila/fox-packages/main/packages.yaml"
 
-const FoxRootPath = "/usr/local/Fox/"
-const FoxBinPath = FoxRootPath + "bin/"
-const FoxVersionPath = "/usr/local/Fox/version"
+// The paths fox installs into. They are variables so that programs embedding fox can move them, see SetRootPath
+var (
+	FoxRootPath    = DefaultFoxRootPath
+	FoxBinPath     string
+	FoxVersionPath string
+	// FoxStorePath keeps the real executables of installations that run through a wrapper, by package and version
+	FoxStorePath string
+	// FoxDownloadsCachePath keeps downloaded release assets by the sha256 of their contents
+	FoxDownloadsCachePath string
+	// FoxRunPath is where 'fox run' kept its executables before they moved to the downloads cache, pruning removes it
+	FoxRunPath string
+	// Man pages and completions shipped by packages
+	FoxSharePath           string
+	FoxManPath             string
+	FoxBashCompletionsPath string
+	FoxZshCompletionsPath  string
+	FoxFishCompletionsPath string
+	CacheFilePath          string
+)
+
+const DefaultFoxRootPath = "/usr/local/Fox/"
+
+// DefaultDownloadCacheSizeMB is the size of the downloads cache when the config doesn't say
+const DefaultDownloadCacheSizeMB = 1024
+
+// DefaultHTTPTimeoutSeconds is how long requests wait for the server when the config doesn't say
+const DefaultHTTPTimeoutSeconds = 30
+
+func init() {
+	SetRootPath(DefaultFoxRootPath)
+}
+
+// SetRootPath moves every path fox uses under root
+func SetRootPath(root string) {
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+
+	FoxRootPath = root
+	FoxBinPath = root + "bin/"
+	FoxVersionPath = root + "version"
+	FoxStorePath = root + "store/"
+	FoxDownloadsCachePath = root + "cache/downloads/"
+	FoxRunPath = root + "run/"
+	FoxSharePath = root + "share/"
+	FoxManPath = FoxSharePath + "man/"
+	FoxBashCompletionsPath = FoxSharePath + "bash-completion/completions/"
+	FoxZshCompletionsPath = FoxSharePath + "zsh/site-functions/"
+	FoxFishCompletionsPath = FoxSharePath + "fish/vendor_completions.d/"
+	CacheFilePath = root + "cache.yaml"
+}
 
 const ConfigFilePath = "/.fox/config.yaml"
 const ConfigDirectoryPath = "/.fox"
 
 const RepositoriesFilePath = "/.fox/repositories.yaml"
 
-const CacheFilePath = FoxRootPath + "cache.yaml"
-
 const Binary = "binary"
 const Script = "script"
 
@@ -27,10 +73,18 @@ const GitHubRateLimit = 5000
 
 var DoNotShow = []string{"gh", "fox"}
 
-var TarExtensions = []string{".tar", ".tar.gz", ".tb2", ".tbz", ".tbz2", ".tgz", ".tlz", ".txz", ".tZ"}
+var TarExtensions = []string{".tar", ".tar.gz", ".tar.bz2", ".tar.xz", ".tar.zst", ".tb2", ".tbz", ".tbz2", ".tgz", ".tlz", ".txz", ".tzst", ".tZ"}
+
+// CompressedExtensions are single compressed files, most likely an executable
+var CompressedExtensions = []string{".gz", ".bz2", ".xz", ".zst"}
 
 var ZIPExtensions = []string{".zip"}
 
+// LinuxPackageExtensions are packages of Linux distributions, fox unpacks them without the package manager
+var LinuxPackageExtensions = []string{".deb", ".rpm", ".apk"}
+
+var AppImageExtensions = []string{".AppImage", ".appimage"}
+
 var Clocks = []string{
 	" 🕐 '･ˎ--ˎ^^- ",
 	" 🕜 ~･ˌ--ˌ^^- ",
@@ -56,33 +110,3 @@ var Clocks = []string{
 	" 🕦 ｡･ˌ--ˌ^^- ",
 	" 🕛 ~･,--,^^- ",
 	" 🕧 '･.--.^^- "}
-
-var MacOS = []string{
-	"macintosh",
-	runtime.GOARCH + "macintosh",
-	"macintosh",
-	"apple" + "darwin" + runtime.GOARCH,
-	"apple" + runtime.GOARCH + "darwin",
-	"apple" + "darwin",
-	"darwin" + "apple",
-	"darwin",
-	"macos" + runtime.GOARCH,
-	runtime.GOARCH + "macos",
-	"macos",
-	"mac",
-	"mac" + runtime.GOARCH,
-	runtime.GOARCH + "mac",
-	"osx" + runtime.GOARCH,
-	runtime.GOARCH + "osx",
-	"osx",
-}
-
-var Linux = []string{
-	"8664" + "linux",
-	"linux" + "8664",
-	"linux64" + "static",
-	"linux64",
-	"amd64" + "linux",
-	"linux" + "amd64",
-	"8664" + "linux",
-}
@@ -0,0 +1,366 @@
+package installations
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// AssetCandidate is a release asset with the score the matching engine gave it and why
+type AssetCandidate struct {
+	Asset repositoriesTypes.Asset
+	Score int
+	// MatchesOS is true when the name mentions the OS of the host
+	MatchesOS bool
+	// Disqualified candidates are never installed, no matter their score
+	Disqualified bool
+	Reasons      []string
+}
+
+func (c *AssetCandidate) add(points int, reason string) {
+	c.Score += points
+	c.Reasons = append(c.Reasons, fmt.Sprintf("%+d %s", points, reason))
+}
+
+func (c *AssetCandidate) disqualify(reason string) {
+	c.Disqualified = true
+	c.Reasons = append(c.Reasons, "✗ "+reason)
+}
+
+// The names each OS and architecture go by in release assets
+var osAliases = map[string][]string{
+	"darwin":  {"darwin", "macos", "mac", "osx", "apple", "macintosh"},
+	"linux":   {"linux"},
+	"windows": {"windows", "win", "win32", "win64", "mingw", "msvc"},
+	"freebsd": {"freebsd"},
+	"openbsd": {"openbsd"},
+	"netbsd":  {"netbsd"},
+	"android": {"android"},
+	"solaris": {"solaris", "illumos"},
+}
+
+var archAliases = map[string][]string{
+	"amd64":   {"amd64", "x8664", "x64", "64bit", "linux64"},
+	"arm64":   {"arm64", "aarch64", "armv8"},
+	"arm":     {"arm", "armv7", "armv7l", "armhf", "armv6", "armv6l", "armv5", "armel", "arm32"},
+	"386":     {"386", "i386", "i686", "x86", "32bit"},
+	"ppc64le": {"ppc64le", "powerpc64le"},
+	"s390x":   {"s390x"},
+	"riscv64": {"riscv64"},
+}
+
+// darwin assets that run on every architecture
+var universalArchitectures = []string{"universal", "universal2", "all", "fat"}
+
+var libcFlavors = map[string][]string{
+	"gnu":  {"gnu", "glibc"},
+	"musl": {"musl", "static"},
+}
+
+// The names each version of 32-bit ARM goes by in release assets
+var armVariants = map[int][]string{
+	5: {"armv5", "armel"},
+	6: {"armv6", "armv6l"},
+	7: {"armv7", "armv7l", "armhf"},
+}
+
+// Files that are never the executable
+var ignoredSuffixes = map[string]string{
+	".sha256":       "is a checksum",
+	".sha256sum":    "is a checksum",
+	".sha512":       "is a checksum",
+	".sha1":         "is a checksum",
+	".md5":          "is a checksum",
+	".sig":          "is a signature",
+	".asc":          "is a signature",
+	".pem":          "is a certificate",
+	".cert":         "is a certificate",
+	".sbom":         "is an SBOM",
+	".spdx":         "is an SBOM",
+	".spdx.json":    "is an SBOM",
+	".cdx.json":     "is an SBOM",
+	".sbom.json":    "is an SBOM",
+	".intoto.jsonl": "is an attestation",
+	".txt":          "is a text file",
+	".json":         "is a metadata file",
+	".yaml":         "is a metadata file",
+	".yml":          "is a metadata file",
+	".msi":          "is a Windows installer",
+	".exe":          "is a Windows executable",
+	".pkg":          "is a macOS installer",
+	".dmg":          "is a macOS disk image",
+	".snap":         "is a Snap package",
+	".flatpak":      "is a Flatpak package",
+	".vsix":         "is an editor extension",
+	".7z":           "is a 7z archive",
+	".rar":          "is a RAR archive",
+}
+
+var preferredArchives = append(append([]string{}, constants.TarExtensions...), constants.ZIPExtensions...)
+
+var tokenSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// the x86-64 microarchitecture level, eg: amd64v3 or x86_64_v3
+var amd64LevelRegex = regexp.MustCompile(`(amd64|x8664)[-_]?v([1-4])`)
+
+// tokenize splits an asset name in lower case words, keeping x86_64 together as x8664
+// and splitting its level apart, eg: amd64v3 becomes amd64 v3
+func tokenize(name string) []string {
+	name = strings.NewReplacer("x86_64", "x8664", "x86-64", "x8664").Replace(strings.ToLower(name))
+	name = amd64LevelRegex.ReplaceAllString(name, "$1 v$2")
+	return lo.Filter(tokenSeparators.Split(name, -1), func(t string, _ int) bool {
+		return t != ""
+	})
+}
+
+// matchesAlias returns the keys of the aliases table that the tokens mention, with the token that mentioned each
+func matchesAlias(tokens []string, aliases map[string][]string) (map[string]string, bool) {
+	found := map[string]string{}
+	for key, names := range aliases {
+		for _, token := range tokens {
+			if lo.Contains(names, token) {
+				found[key] = token
+			}
+		}
+	}
+
+	return found, len(found) > 0
+}
+
+// ScoreAsset scores how likely an asset is to be the executable of the package for the host
+func ScoreAsset(asset repositoriesTypes.Asset, pkg repositoriesTypes.Package, host Host) AssetCandidate {
+	candidate := AssetCandidate{Asset: asset}
+	lowerName := strings.ToLower(asset.Name)
+	tokens := tokenize(asset.Name)
+
+	// longest suffixes first, so .tar.xz is not reported as .xz
+	suffixes := lo.Keys(ignoredSuffixes)
+	sort.Slice(suffixes, func(i, j int) bool {
+		return len(suffixes[i]) > len(suffixes[j])
+	})
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(lowerName, suffix) && !lo.ContainsBy(preferredArchives, func(a string) bool {
+			return strings.HasSuffix(lowerName, a)
+		}) {
+			candidate.disqualify(ignoredSuffixes[suffix])
+			break
+		}
+	}
+
+	if lo.Contains(tokens, "checksums") || lo.Contains(tokens, "sbom") || lo.Contains(tokens, "src") || lo.Contains(tokens, "source") {
+		candidate.disqualify("is not an executable")
+	}
+
+	oses, hasOS := matchesAlias(tokens, osAliases)
+	// Linux packages and AppImages rarely say linux in the name, the format already tells
+	linuxOnly := utils.FileHasLinuxPackageExtension(lowerName) || utils.FileHasAppImageExtension(lowerName)
+	if linuxOnly && !hasOS {
+		oses, hasOS = map[string]string{"linux": lowerName[strings.LastIndex(lowerName, ".")+1:]}, true
+	}
+	if _, ok := oses[host.OS]; ok {
+		candidate.MatchesOS = true
+		candidate.add(40, "mentions your OS ("+oses[host.OS]+")")
+	} else if hasOS {
+		candidate.disqualify("is for another OS (" + strings.Join(lo.Values(oses), ", ") + ")")
+	} else {
+		candidate.Reasons = append(candidate.Reasons, "+0 does not mention an OS")
+	}
+
+	architectures, hasArch := matchesAlias(tokens, archAliases)
+	if _, ok := architectures[host.Arch]; ok {
+		candidate.add(30, "mentions your architecture ("+architectures[host.Arch]+")")
+	} else if _, ok := architectures["amd64"]; ok && host.OS == "darwin" && host.Arch == "arm64" {
+		candidate.add(10, "is for amd64, which runs under Rosetta 2")
+	} else if hasArch {
+		candidate.disqualify("is for another architecture (" + strings.Join(lo.Values(architectures), ", ") + ")")
+	} else if host.OS == "darwin" && lo.Some(tokens, universalArchitectures) {
+		candidate.add(25, "is a universal macOS binary")
+	} else {
+		candidate.add(5, "does not mention an architecture, it could be for any")
+	}
+
+	if host.OS == "linux" {
+		scoreLibc(&candidate, tokens, host)
+	}
+
+	if host.Arch == "arm" {
+		scoreARMVariant(&candidate, tokens, host)
+	}
+
+	if host.Arch == "amd64" {
+		scoreAMD64Level(&candidate, tokens, host)
+	}
+
+	if lo.ContainsBy(preferredArchives, func(a string) bool {
+		return strings.HasSuffix(lowerName, strings.ToLower(a))
+	}) {
+		candidate.add(5, "is an archive fox can extract")
+	} else if utils.FileHasCompressedExtension(lowerName) {
+		candidate.add(3, "is a compressed executable")
+	} else if utils.FileHasLinuxPackageExtension(lowerName) {
+		// below archives, a package is more likely to depend on things the distribution provides
+		candidate.add(2, "is a Linux package fox can unpack")
+	} else if utils.FileHasAppImageExtension(lowerName) {
+		candidate.add(3, "is an AppImage, a self-contained executable")
+	} else if !strings.Contains(asset.Name, ".") {
+		candidate.add(3, "looks like a plain executable")
+	}
+
+	if pkg.ExecutableName != "" && strings.HasPrefix(lowerName, strings.ToLower(pkg.ExecutableName)) {
+		candidate.add(10, "starts with the executable name")
+	}
+
+	return candidate
+}
+
+// scoreLibc prefers the assets built for the libc of the host. glibc builds don't run on musl,
+// musl and static builds run everywhere
+func scoreLibc(candidate *AssetCandidate, tokens []string, host Host) {
+	libc, hasLibc := matchesAlias(tokens, libcFlavors)
+	if !hasLibc {
+		return
+	}
+
+	_, gnu := libc["gnu"]
+	_, musl := libc["musl"]
+	switch {
+	case host.Libc == "musl" && musl:
+		candidate.add(10, "is statically linked or uses musl, like your system")
+	case host.Libc == "musl" && gnu:
+		candidate.disqualify("is linked against glibc, but your system uses musl")
+	case gnu:
+		candidate.add(5, "is linked against glibc")
+	case musl:
+		candidate.add(2, "is statically linked or uses musl")
+	}
+}
+
+// scoreARMVariant prefers the assets built for the version of ARM of the host.
+// Newer versions run binaries built for older ones, not the other way around
+func scoreARMVariant(candidate *AssetCandidate, tokens []string, host Host) {
+	// the newest version the name mentions decides, and always the same one
+	variants := lo.Keys(armVariants)
+	sort.Sort(sort.Reverse(sort.IntSlice(variants)))
+	for _, variant := range variants {
+		if !lo.Some(tokens, armVariants[variant]) {
+			continue
+		}
+
+		switch {
+		case host.ARMVariant == 0:
+			return
+		case variant == host.ARMVariant:
+			candidate.add(5, fmt.Sprintf("is built for armv%d, like your CPU", variant))
+		case variant < host.ARMVariant:
+			candidate.add(2, fmt.Sprintf("is built for armv%d, which your CPU can run", variant))
+		default:
+			candidate.disqualify(fmt.Sprintf("is built for armv%d, but your CPU is armv%d", variant, host.ARMVariant))
+		}
+
+		return
+	}
+}
+
+// scoreAMD64Level prefers the assets built for the highest x86-64 level the CPU of the host supports
+func scoreAMD64Level(candidate *AssetCandidate, tokens []string, host Host) {
+	level := 0
+	for i := 1; i < len(tokens); i++ {
+		if lo.Contains(archAliases["amd64"], tokens[i-1]) && len(tokens[i]) == 2 && tokens[i][0] == 'v' && tokens[i][1] >= '1' && tokens[i][1] <= '4' {
+			level = int(tokens[i][1] - '0')
+			break
+		}
+	}
+
+	switch {
+	case level == 0:
+		return
+	case host.AMD64Level == 0:
+		// better safe than sorry, fewer CPUs support higher levels
+		candidate.add(-level, fmt.Sprintf("is built for x86-64-v%d, and fox couldn't tell what your CPU supports", level))
+	case level > host.AMD64Level:
+		candidate.disqualify(fmt.Sprintf("is built for x86-64-v%d, but your CPU only supports x86-64-v%d", level, host.AMD64Level))
+	default:
+		candidate.add(level, fmt.Sprintf("is built for x86-64-v%d, which your CPU supports", level))
+	}
+}
+
+// RankAssets scores every asset of the release, the best candidate first
+func RankAssets(assets []repositoriesTypes.Asset, pkg repositoriesTypes.Package, host Host) []AssetCandidate {
+	candidates := lo.Map(assets, func(a repositoriesTypes.Asset, _ int) AssetCandidate {
+		return ScoreAsset(a, pkg, host)
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Disqualified != candidates[j].Disqualified {
+			return !candidates[i].Disqualified
+		}
+
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+
+		// the shortest name has the least amount of surprises
+		return len(candidates[i].Asset.Name) < len(candidates[j].Asset.Name)
+	})
+
+	return candidates
+}
+
+// BestAsset returns the best candidate, as long as it is not disqualified and names the OS of the host
+func BestAsset(candidates []AssetCandidate) *repositoriesTypes.Asset {
+	if len(candidates) == 0 || candidates[0].Disqualified {
+		return nil
+	}
+
+	// an asset that doesn't mention the OS is only good enough if it is the only option
+	if !candidates[0].MatchesOS && qualified(candidates) > 1 {
+		return nil
+	}
+
+	return &candidates[0].Asset
+}
+
+// CompatibleAssets returns the assets of the release that can run on the host, the one fox would install first
+func CompatibleAssets(release repositoriesTypes.Release, pkg repositoriesTypes.Package, host Host) []AssetCandidate {
+	candidates := RankAssets(release.Assets, pkg, host)
+	count := qualified(candidates)
+	return lo.Filter(candidates, func(c AssetCandidate, _ int) bool {
+		// like BestAsset, an asset that doesn't mention the OS is only good enough if it is the only one
+		return !c.Disqualified && (c.MatchesOS || count == 1)
+	})
+}
+
+// qualified counts the candidates that are not disqualified, eg: checksums don't count
+func qualified(candidates []AssetCandidate) int {
+	return lo.CountBy(candidates, func(c AssetCandidate) bool {
+		return !c.Disqualified
+	})
+}
+
+// PrintCandidates prints the ranked assets and why, for bug reports when the wrong asset is chosen
+func PrintCandidates(candidates []AssetCandidate, host Host) {
+	color.Magenta(" Ranked assets for %s:", host)
+	for i, c := range candidates {
+		line := fmt.Sprintf("   %2d. [%3d] %s", i+1, c.Score, c.Asset.Name)
+		if c.Disqualified {
+			color.Red(line)
+		} else if i == 0 {
+			color.Green(line)
+		} else {
+			color.White(line)
+		}
+
+		for _, reason := range c.Reasons {
+			fmt.Fprintln(color.Output, "             "+reason)
+		}
+	}
+	fmt.Fprintln(color.Output)
+}
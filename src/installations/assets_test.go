@@ -0,0 +1,65 @@
+package installations
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+func assets(names ...string) []repositoriesTypes.Asset {
+	return lo.Map(names, func(name string, _ int) repositoriesTypes.Asset {
+		return repositoriesTypes.Asset{Name: name}
+	})
+}
+
+func TestBestAsset(t *testing.T) {
+	pkg := repositoriesTypes.Package{ExecutableName: "tool", Type: "binary"}
+	linux := Host{OS: "linux", Arch: "amd64"}
+	tests := []struct {
+		name   string
+		assets []repositoriesTypes.Asset
+		host   Host
+		want   string
+	}{
+		{name: "the only asset doesn't mention an OS", assets: assets("tool"), host: linux, want: "tool"},
+		{name: "checksums don't count as other options", assets: assets("tool", "tool.sha256", "tool.sig"), host: linux, want: "tool"},
+		{name: "several assets without an OS", assets: assets("tool", "tool-extra"), host: linux},
+		{name: "the asset for the OS", assets: assets("tool_darwin_arm64.tar.gz", "tool_linux_amd64.tar.gz", "checksums.txt"), host: linux, want: "tool_linux_amd64.tar.gz"},
+		{name: "armv5 on another architecture", assets: assets("tool_linux_armv5"), host: linux},
+		{
+			name:   "armv5 on an armv7 CPU",
+			assets: assets("tool_linux_armv5", "tool_linux_armv7"),
+			host:   Host{OS: "linux", Arch: "arm", ARMVariant: 7},
+			want:   "tool_linux_armv7",
+		},
+		{
+			name:   "an asset that mentions several ARM versions",
+			assets: assets("tool_linux_armel_armv7"),
+			host:   Host{OS: "linux", Arch: "arm", ARMVariant: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the ranking must not depend on the order of maps, run it a few times
+			for i := 0; i < 20; i++ {
+				best := BestAsset(RankAssets(tt.assets, pkg, tt.host))
+				got := ""
+				if best != nil {
+					got = best.Name
+				}
+
+				if got != tt.want {
+					t.Fatalf("expected %q, got %q", tt.want, got)
+				}
+			}
+
+			compatible := CompatibleAssets(repositoriesTypes.Release{Assets: tt.assets}, pkg, tt.host)
+			if tt.want != "" && (len(compatible) == 0 || compatible[0].Asset.Name != tt.want) {
+				t.Errorf("expected %q to be the first compatible asset, got %v", tt.want, compatible)
+			}
+		})
+	}
+}
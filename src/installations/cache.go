@@ -0,0 +1,309 @@
+package installations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+func downloadCacheIndexPath() string {
+	return constants.FoxDownloadsCachePath + "index.yaml"
+}
+
+func downloadObjectPath(digest string) string {
+	return constants.FoxDownloadsCachePath + "objects/" + digest
+}
+
+// DownloadCacheLimit returns the size limit of the downloads cache in bytes, 0 when it is disabled
+func DownloadCacheLimit(userConfig types.UserConfig) int64 {
+	if userConfig.DownloadCacheSizeMB < 0 {
+		return 0
+	}
+
+	sizeMB := lo.Ternary(userConfig.DownloadCacheSizeMB == 0, int64(constants.DefaultDownloadCacheSizeMB), userConfig.DownloadCacheSizeMB)
+	return sizeMB * 1024 * 1024
+}
+
+func LoadDownloadCache() (types.DownloadCache, error) {
+	var cache types.DownloadCache
+	if !utils.FileExists(downloadCacheIndexPath()) {
+		return cache, nil
+	}
+
+	data, err := os.ReadFile(downloadCacheIndexPath())
+	if err != nil {
+		return cache, err
+	}
+
+	err = yaml.Unmarshal(data, &cache)
+	if err != nil {
+		return cache, utils.Classify(utils.ErrStateCorruption, fmt.Errorf("Error reading the downloads cache index at "+downloadCacheIndexPath()+": %w", err))
+	}
+
+	return cache, nil
+}
+
+func saveDownloadCache(cache types.DownloadCache) error {
+	err := utils.CreateDirectoryIfNotExists(constants.FoxDownloadsCachePath)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&cache)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(downloadCacheIndexPath(), data, 0666)
+}
+
+// DownloadCacheSize returns the size of every file the cache keeps, the same download can be indexed twice
+func DownloadCacheSize(cache types.DownloadCache) int64 {
+	size := int64(0)
+	for _, d := range lo.UniqBy(cache.Downloads, func(d types.CachedDownload) string {
+		return d.SHA256
+	}) {
+		size += d.Size
+	}
+
+	return size
+}
+
+// fetchAsset downloads the asset into the current directory, from the downloads cache when it is already there
+func fetchAsset(ctx context.Context, asset repositoriesTypes.Asset, repo string, release repositoriesTypes.Release, userConfig types.UserConfig) error {
+	// the tag is not part of the asset in the GitHub API
+	if asset.Tag == "" {
+		asset.Tag = release.Tag
+	}
+
+	limit := DownloadCacheLimit(userConfig)
+	if limit == 0 || asset.ID == 0 {
+		return asset.DownloadAsset(ctx, repo)
+	}
+
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return err
+	}
+
+	cached, found := lo.Find(cache.Downloads, func(d types.CachedDownload) bool {
+		return d.AssetID == asset.ID
+	})
+
+	if found {
+		// a corrupted or partial file is dropped and downloaded again
+		digest, size, err := utils.FileDigest(downloadObjectPath(cached.SHA256))
+		if err == nil && digest == cached.SHA256 && size == cached.Size {
+			err = utils.CopyFile(downloadObjectPath(cached.SHA256), "./"+asset.Name)
+			if err != nil {
+				return err
+			}
+
+			color.Green(" Using the cached download of %s", asset.Name)
+			return touchCachedDownload(asset.ID)
+		}
+	}
+
+	err = asset.DownloadAsset(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	return addToDownloadCache(asset, repo, limit)
+}
+
+func touchCachedDownload(assetID int) error {
+	return touchCached(func(d types.CachedDownload) bool {
+		return d.AssetID == assetID
+	})
+}
+
+// touchCached marks the matching downloads as just used, so they are the last ones evicted
+func touchCached(matches func(d types.CachedDownload) bool) error {
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return err
+	}
+
+	for i := range cache.Downloads {
+		if matches(cache.Downloads[i]) {
+			cache.Downloads[i].LastUsed = time.Now().UnixMilli()
+		}
+	}
+
+	return saveDownloadCache(cache)
+}
+
+func addToDownloadCache(asset repositoriesTypes.Asset, repo string, limit int64) error {
+	digest, size, err := utils.FileDigest("./" + asset.Name)
+	if err != nil {
+		return err
+	}
+
+	// it would evict everything else and still not fit
+	if size > limit {
+		return nil
+	}
+
+	err = utils.CreateDirectoryIfNotExists(constants.FoxDownloadsCachePath + "objects/")
+	if err != nil {
+		return err
+	}
+
+	if !utils.FileExists(downloadObjectPath(digest)) {
+		err = utils.CopyFile("./"+asset.Name, downloadObjectPath(digest))
+		if err != nil {
+			return err
+		}
+	}
+
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return err
+	}
+
+	cache.Downloads = lo.Filter(cache.Downloads, func(d types.CachedDownload, _ int) bool {
+		return d.AssetID != asset.ID
+	})
+	cache.Downloads = append(cache.Downloads, types.CachedDownload{
+		AssetID:  asset.ID,
+		Name:     asset.Name,
+		Repo:     repo,
+		Tag:      asset.Tag,
+		SHA256:   digest,
+		Size:     size,
+		LastUsed: time.Now().UnixMilli(),
+	})
+
+	cache, _, err = evictDownloads(cache, limit)
+	if err != nil {
+		return err
+	}
+
+	return saveDownloadCache(cache)
+}
+
+// addRunExecutable indexes an executable of 'fox run', already in the objects of the cache, under every key.
+// It must fit in the limit, see uncachedRunExecutable
+func addRunExecutable(pkg repositoriesTypes.Package, tag, digest string, size int64, keys []string, limit int64) error {
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return err
+	}
+
+	cache.Downloads = lo.Filter(cache.Downloads, func(d types.CachedDownload, _ int) bool {
+		return !lo.Contains(keys, d.RunKey)
+	})
+	for _, key := range keys {
+		cache.Downloads = append(cache.Downloads, types.CachedDownload{
+			Name:     pkg.ExecutableName,
+			Repo:     pkg.NameWithOwner,
+			Tag:      tag,
+			SHA256:   digest,
+			Size:     size,
+			LastUsed: time.Now().UnixMilli(),
+			RunKey:   key,
+		})
+	}
+
+	cache, _, err = evictDownloads(cache, limit)
+	if err != nil {
+		return err
+	}
+
+	return saveDownloadCache(cache)
+}
+
+// evictDownloads removes the least recently used downloads until the cache fits in the limit
+func evictDownloads(cache types.DownloadCache, limit int64) (types.DownloadCache, []types.CachedDownload, error) {
+	sort.SliceStable(cache.Downloads, func(i, j int) bool {
+		return cache.Downloads[i].LastUsed > cache.Downloads[j].LastUsed
+	})
+
+	var evicted []types.CachedDownload
+	for DownloadCacheSize(cache) > limit && len(cache.Downloads) > 0 {
+		oldest := cache.Downloads[len(cache.Downloads)-1]
+		cache.Downloads = cache.Downloads[:len(cache.Downloads)-1]
+		evicted = append(evicted, oldest)
+
+		stillUsed := lo.ContainsBy(cache.Downloads, func(d types.CachedDownload) bool {
+			return d.SHA256 == oldest.SHA256
+		})
+		if stillUsed {
+			continue
+		}
+
+		err := utils.RemoveFile(downloadObjectPath(oldest.SHA256))
+		if err != nil {
+			return cache, evicted, err
+		}
+	}
+
+	return cache, evicted, nil
+}
+
+// PruneDownloadCache evicts downloads over the limit, and drops index entries and files that don't match each other
+func PruneDownloadCache(userConfig types.UserConfig) ([]types.CachedDownload, error) {
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return nil, err
+	}
+
+	missing := lo.Filter(cache.Downloads, func(d types.CachedDownload, _ int) bool {
+		return !utils.FileExists(downloadObjectPath(d.SHA256))
+	})
+	cache.Downloads = lo.Filter(cache.Downloads, func(d types.CachedDownload, _ int) bool {
+		return utils.FileExists(downloadObjectPath(d.SHA256))
+	})
+
+	cache, evicted, err := evictDownloads(cache, DownloadCacheLimit(userConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	// where 'fox run' used to keep its executables
+	err = utils.RemoveDirectory(constants.FoxRunPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// files no entry points to, eg: left behind by an interrupted download
+	objects, err := os.ReadDir(constants.FoxDownloadsCachePath + "objects/")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, object := range objects {
+		if lo.ContainsBy(cache.Downloads, func(d types.CachedDownload) bool { return d.SHA256 == object.Name() }) {
+			continue
+		}
+
+		err = utils.RemoveFile(downloadObjectPath(object.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(missing, evicted...), saveDownloadCache(cache)
+}
+
+// ClearDownloadCache removes every cached download, and the executables of 'fox run'
+func ClearDownloadCache() error {
+	err := utils.RemoveDirectory(constants.FoxRunPath)
+	if err != nil {
+		return err
+	}
+
+	return utils.RemoveDirectory(constants.FoxDownloadsCachePath)
+}
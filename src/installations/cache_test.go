@@ -0,0 +1,118 @@
+package installations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+func TestEvictDownloads(t *testing.T) {
+	download := func(name, digest string, size, lastUsed int64) types.CachedDownload {
+		return types.CachedDownload{Name: name, SHA256: digest, Size: size, LastUsed: lastUsed}
+	}
+
+	tests := []struct {
+		name      string
+		downloads []types.CachedDownload
+		limit     int64
+		// kept and evicted are names of downloads, kept from the most recently used
+		kept    []string
+		evicted []string
+	}{
+		{
+			name:      "fits",
+			downloads: []types.CachedDownload{download("a", "1", 10, 1), download("b", "2", 10, 2)},
+			limit:     20,
+			kept:      []string{"b", "a"},
+		},
+		{
+			name:      "least recently used first",
+			downloads: []types.CachedDownload{download("a", "1", 10, 3), download("b", "2", 10, 1), download("c", "3", 10, 2)},
+			limit:     20,
+			kept:      []string{"a", "c"},
+			evicted:   []string{"b"},
+		},
+		{
+			name:      "until it fits",
+			downloads: []types.CachedDownload{download("a", "1", 5, 1), download("b", "2", 5, 2), download("c", "3", 30, 3)},
+			limit:     30,
+			kept:      []string{"c"},
+			evicted:   []string{"a", "b"},
+		},
+		{
+			name:      "a big recent download evicts small old ones",
+			downloads: []types.CachedDownload{download("big", "1", 25, 3), download("a", "2", 5, 2), download("b", "3", 5, 1)},
+			limit:     30,
+			kept:      []string{"big", "a"},
+			evicted:   []string{"b"},
+		},
+		{
+			name:      "the same file indexed twice counts once",
+			downloads: []types.CachedDownload{download("tag", "1", 20, 2), download("name", "1", 20, 1), download("other", "2", 10, 3)},
+			limit:     30,
+			kept:      []string{"other", "tag", "name"},
+		},
+		{
+			name:      "disabled",
+			downloads: []types.CachedDownload{download("a", "1", 1, 2), download("b", "2", 1, 1)},
+			limit:     0,
+			evicted:   []string{"b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inTempRoot(t)
+			for _, d := range tt.downloads {
+				writeFile(t, downloadObjectPath(d.SHA256), "object")
+			}
+
+			cache, evicted, err := evictDownloads(types.DownloadCache{Downloads: tt.downloads}, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := func(downloads []types.CachedDownload) []string {
+				return lo.Map(downloads, func(d types.CachedDownload, _ int) string { return d.Name })
+			}
+
+			if kept := names(cache.Downloads); fmt.Sprint(kept) != fmt.Sprint(tt.kept) {
+				t.Errorf("expected to keep %v, kept %v", tt.kept, kept)
+			}
+
+			if got := names(evicted); fmt.Sprint(got) != fmt.Sprint(tt.evicted) {
+				t.Errorf("expected to evict %v, evicted %v", tt.evicted, got)
+			}
+
+			// the files of evicted downloads go, unless a download that is kept shares them
+			for _, d := range tt.downloads {
+				stillUsed := lo.ContainsBy(cache.Downloads, func(k types.CachedDownload) bool { return k.SHA256 == d.SHA256 })
+				if exists := utils.FileExists(downloadObjectPath(d.SHA256)); exists != stillUsed {
+					t.Errorf("%s: expected the file to exist %t, it exists %t", d.Name, stillUsed, exists)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadCacheLimit(t *testing.T) {
+	tests := []struct {
+		sizeMB int64
+		limit  int64
+	}{
+		{sizeMB: -1, limit: 0},
+		{sizeMB: 1, limit: 1024 * 1024},
+		{sizeMB: 0, limit: constants.DefaultDownloadCacheSizeMB * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		if limit := DownloadCacheLimit(types.UserConfig{DownloadCacheSizeMB: tt.sizeMB}); limit != tt.limit {
+			t.Errorf("%d MB: expected %d, got %d", tt.sizeMB, tt.limit, limit)
+		}
+	}
+}
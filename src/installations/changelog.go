@@ -0,0 +1,42 @@
+package installations
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// eg: "BREAKING CHANGE: ...", "**Breaking**: ...", "⚠️ removed the --old flag"
+var breakingRegex = regexp.MustCompile(`(?i)\bbreaking\b|⚠|\bbackwards?[- ]incompatible\b`)
+
+// IsBreaking tells if a line of release notes announces a breaking change
+func IsBreaking(line string) bool {
+	return breakingRegex.MatchString(line)
+}
+
+// HasBreakingChanges tells if the notes of any of the releases announce a breaking change
+func HasBreakingChanges(releases []repositoriesTypes.Release) bool {
+	return lo.ContainsBy(releases, func(r repositoriesTypes.Release) bool {
+		return lo.ContainsBy(strings.Split(r.Body, "\n"), IsBreaking)
+	})
+}
+
+// ReleasesSince returns the releases newer than the installed version, newest first like GitHub returns them.
+// Prereleases are left out, upgrades only go to the latest stable version.
+// If the installed version is not among the releases, eg: it was deleted, all of them are returned and found is false.
+func ReleasesSince(releases []repositoriesTypes.Release, installedVersion string) (since []repositoriesTypes.Release, found bool) {
+	for _, r := range releases {
+		if strings.EqualFold(strings.TrimSpace(r.Tag), strings.TrimSpace(installedVersion)) {
+			return since, true
+		}
+
+		if !r.Prerelease {
+			since = append(since, r)
+		}
+	}
+
+	return since, false
+}
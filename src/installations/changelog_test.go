@@ -0,0 +1,35 @@
+package installations
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// releases are newest first, like GitHub returns them
+var prereleases = []repositoriesTypes.Release{
+	{Tag: "v2.0.0-rc1", Prerelease: true},
+	{Tag: "v1.2.0"},
+	{Tag: "v1.2.0-beta", Prerelease: true},
+	{Tag: "v1.1.0"},
+}
+
+func tags(releases []repositoriesTypes.Release) []string {
+	return lo.Map(releases, func(r repositoriesTypes.Release, _ int) string {
+		return r.Tag
+	})
+}
+
+func TestReleasesSince(t *testing.T) {
+	since, found := ReleasesSince(prereleases, "v1.1.0")
+	if !found || len(since) != 1 || since[0].Tag != "v1.2.0" {
+		t.Errorf("expected only v1.2.0 since v1.1.0, got %v (found: %t)", tags(since), found)
+	}
+
+	since, found = ReleasesSince(prereleases, "v0.1.0")
+	if found || len(since) != 2 {
+		t.Errorf("expected the stable releases when the installed version is missing, got %v (found: %t)", tags(since), found)
+	}
+}
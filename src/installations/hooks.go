@@ -0,0 +1,159 @@
+package installations
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+func approvedHooksPath() string {
+	return constants.FoxRootPath + "approved-hooks.yaml"
+}
+
+// HookEnvironment is what a hook knows about the installation it runs for
+type HookEnvironment struct {
+	Package string
+	Version string
+	// Bin is the path of the executable in constants.FoxBinPath
+	Bin string
+}
+
+func loadApprovedHooks() (types.ApprovedHooks, error) {
+	approved := types.ApprovedHooks{Packages: map[string][]string{}}
+	if !utils.FileExists(approvedHooksPath()) {
+		return approved, nil
+	}
+
+	data, err := os.ReadFile(approvedHooksPath())
+	if err != nil {
+		return approved, err
+	}
+
+	err = yaml.Unmarshal(data, &approved)
+	if err != nil {
+		return approved, utils.Classify(utils.ErrStateCorruption, fmt.Errorf("Error reading the approved hooks at "+approvedHooksPath()+": %w", err))
+	}
+
+	if approved.Packages == nil {
+		approved.Packages = map[string][]string{}
+	}
+
+	return approved, nil
+}
+
+func saveApprovedHooks(approved types.ApprovedHooks) error {
+	data, err := yaml.Marshal(&approved)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(approvedHooksPath(), data, 0666)
+}
+
+// IsHookApproved tells if the user already approved the command for the package
+func IsHookApproved(packageName, command string) bool {
+	approved, err := loadApprovedHooks()
+	if err != nil {
+		return false
+	}
+
+	return lo.Contains(approved.Packages[packageName], command)
+}
+
+// ApproveHooks shows the hooks of the package the user hasn't approved yet and asks for confirmation.
+// Once approved, the same commands don't ask again. When not interactive, unapproved hooks are never run.
+func ApproveHooks(pkg repositoriesTypes.Package, interactive bool) (bool, error) {
+	pending := lo.Filter(pkg.Hooks.Commands(), func(c string, _ int) bool {
+		return !IsHookApproved(pkg.NameWithOwner, c)
+	})
+
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	if !interactive {
+		color.Yellow(" Warning: skipping the hooks of %s, they have not been approved yet.", pkg.ExecutableName)
+		color.Yellow(" Install it once without -y to review them.")
+		return false, nil
+	}
+
+	color.Yellow(" %s runs the following commands on your system:", pkg.ExecutableName)
+	for _, hook := range []struct{ name, command string }{
+		{"preInstall", pkg.Hooks.PreInstall},
+		{"postInstall", pkg.Hooks.PostInstall},
+		{"preUninstall", pkg.Hooks.PreUninstall},
+	} {
+		if lo.Contains(pending, hook.command) {
+			color.White("   %s: %s", hook.name, hook.command)
+		}
+	}
+
+	prompt := promptui.Select{
+		Label: " Allow them?",
+		Items: []string{"Yes", "No"},
+	}
+
+	_, result, err := prompt.Run()
+	if err != nil || result == "No" {
+		color.Yellow(" The hooks of %s will be skipped.", pkg.ExecutableName)
+		return false, nil
+	}
+
+	approved, err := loadApprovedHooks()
+	if err != nil {
+		return false, err
+	}
+
+	approved.Packages[pkg.NameWithOwner] = lo.Uniq(append(approved.Packages[pkg.NameWithOwner], pending...))
+	return true, saveApprovedHooks(approved)
+}
+
+// hookVariables are the only variables of the environment of fox that hooks see, eg: not GH_TOKEN
+var hookVariables = []string{"PATH", "HOME"}
+
+// hookEnviron builds the environment of a hook, any FOX_ variable of the user is kept too
+func hookEnviron(env HookEnvironment) []string {
+	environ := lo.Filter(os.Environ(), func(variable string, _ int) bool {
+		name, _, _ := strings.Cut(variable, "=")
+		return lo.Contains(hookVariables, name) || strings.HasPrefix(name, "FOX_")
+	})
+
+	return append(environ,
+		"FOX_PACKAGE="+env.Package,
+		"FOX_VERSION="+env.Version,
+		"FOX_BIN="+env.Bin,
+	)
+}
+
+// RunHook runs the command with 'sh -c' from the home directory of the user, with a minimal environment
+func RunHook(name, command string, env HookEnvironment) error {
+	if command == "" {
+		return nil
+	}
+
+	color.Magenta(" Running the %s hook of %s: %s", name, env.Package, command)
+	hook := exec.Command("sh", "-c", command)
+	hook.Env = hookEnviron(env)
+	hook.Dir, _ = os.UserHomeDir()
+	// the output of fox, eg: the progress pane of 'fox list'
+	hook.Stdout = color.Output
+	hook.Stderr = color.Output
+
+	err := hook.Run()
+	if err != nil {
+		return fmt.Errorf("the %s hook of %s failed: %s", name, env.Package, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package installations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookEnviron(t *testing.T) {
+	t.Setenv("GH_TOKEN", "secret")
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("FOX_DEBUG", "1")
+
+	environ := strings.Join(hookEnviron(HookEnvironment{Package: "tool", Version: "v1.0.0", Bin: "/usr/local/Fox/bin/tool"}), "\n")
+	for _, variable := range []string{"FOX_PACKAGE=tool", "FOX_VERSION=v1.0.0", "FOX_BIN=/usr/local/Fox/bin/tool", "FOX_DEBUG=1", "PATH="} {
+		if !strings.Contains(environ, variable) {
+			t.Errorf("expected %s in the environment of the hook", variable)
+		}
+	}
+
+	if strings.Contains(environ, "TOKEN") {
+		t.Errorf("the environment of the hook leaks a token:\n%s", environ)
+	}
+}
@@ -0,0 +1,170 @@
+package installations
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// Host is the platform release assets are matched against
+type Host struct {
+	OS   string
+	Arch string
+	// Libc is "gnu" or "musl" on Linux, empty when it couldn't be detected or doesn't apply
+	Libc string
+	// AMD64Level is the x86-64 microarchitecture level (1 to 4), 0 when it couldn't be detected or doesn't apply
+	AMD64Level int
+	// ARMVariant is the version of 32-bit ARM, eg: 6 for armv6l, 0 when it couldn't be detected or doesn't apply
+	ARMVariant int
+}
+
+var (
+	currentHost     Host
+	currentHostOnce sync.Once
+)
+
+// CurrentHost detects the platform fox runs on, only once since it may need to run commands
+func CurrentHost() Host {
+	currentHostOnce.Do(func() {
+		currentHost = Host{OS: runtime.GOOS, Arch: runtime.GOARCH}
+		if currentHost.OS != "linux" {
+			return
+		}
+
+		currentHost.Libc = detectLibc()
+		cpuinfo, _ := os.ReadFile("/proc/cpuinfo")
+		switch currentHost.Arch {
+		case "amd64":
+			currentHost.AMD64Level = detectAMD64Level(string(cpuinfo))
+		case "arm":
+			currentHost.ARMVariant = detectARMVariant(string(cpuinfo))
+		}
+	})
+
+	return currentHost
+}
+
+// String describes the host, eg: linux/amd64 (musl, x86-64-v3)
+func (h Host) String() string {
+	var details []string
+	if h.Libc != "" {
+		details = append(details, lo.Ternary(h.Libc == "gnu", "glibc", h.Libc))
+	}
+
+	if h.AMD64Level > 0 {
+		details = append(details, "x86-64-v"+strconv.Itoa(h.AMD64Level))
+	}
+
+	if h.ARMVariant > 0 {
+		details = append(details, "armv"+strconv.Itoa(h.ARMVariant))
+	}
+
+	description := h.OS + "/" + h.Arch
+	if len(details) > 0 {
+		description += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	return description
+}
+
+// detectLibc tells if the system uses musl, like Alpine, or glibc
+func detectLibc() string {
+	var libraries []string
+	for _, pattern := range []string{"/lib/ld-musl-*", "/lib*/*/libc.so.6", "/lib*/libc.so.6"} {
+		matches, _ := filepath.Glob(pattern)
+		libraries = append(libraries, matches...)
+	}
+
+	// musl's ldd prints its version to stderr and exits with 1, so the error doesn't matter
+	out, _ := exec.Command("ldd", "--version").CombinedOutput()
+
+	return libcOf(libraries, string(out))
+}
+
+// libcOf tells the libc from the loaders and libcs found in the system and the output of 'ldd --version'.
+// The musl loader wins, glibc can be installed next to musl for compatibility
+func libcOf(libraries []string, lddOutput string) string {
+	if lo.ContainsBy(libraries, func(path string) bool { return strings.HasPrefix(filepath.Base(path), "ld-musl-") }) {
+		return "musl"
+	}
+
+	lowerOut := strings.ToLower(lddOutput)
+	if strings.Contains(lowerOut, "musl") {
+		return "musl"
+	}
+
+	if strings.Contains(lowerOut, "glibc") || strings.Contains(lowerOut, "gnu libc") || strings.Contains(lowerOut, "gnu c library") {
+		return "gnu"
+	}
+
+	if lo.ContainsBy(libraries, func(path string) bool { return filepath.Base(path) == "libc.so.6" }) {
+		return "gnu"
+	}
+
+	return ""
+}
+
+// The CPU flags each x86-64 microarchitecture level requires, on top of the previous level
+var amd64LevelFlags = [][]string{
+	2: {"cx16", "lahf_lm", "popcnt", "sse4_1", "sse4_2", "ssse3"},
+	3: {"avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave"},
+	4: {"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"},
+}
+
+// detectAMD64Level reads the flags of the CPU from /proc/cpuinfo
+func detectAMD64Level(cpuinfo string) int {
+	var flags []string
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found && strings.TrimSpace(key) == "flags" {
+			flags = strings.Fields(value)
+			break
+		}
+	}
+
+	if len(flags) == 0 {
+		return 0
+	}
+
+	level := 1
+	for l := 2; l < len(amd64LevelFlags); l++ {
+		if !lo.Every(flags, amd64LevelFlags[l]) {
+			break
+		}
+		level = l
+	}
+
+	return level
+}
+
+var armVariantRegex = regexp.MustCompile(`^armv(\d)`)
+
+// detectARMVariant uses the machine name, eg: armv7l, and falls back to /proc/cpuinfo
+func detectARMVariant(cpuinfo string) int {
+	out, _ := exec.Command("uname", "-m").Output()
+	return armVariantOf(string(out), cpuinfo)
+}
+
+// armVariantOf reads the variant from the output of 'uname -m', or else the CPU architecture in /proc/cpuinfo
+func armVariantOf(machine, cpuinfo string) int {
+	if match := armVariantRegex.FindStringSubmatch(strings.TrimSpace(machine)); match != nil {
+		return int(match[1][0] - '0')
+	}
+
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found && strings.TrimSpace(key) == "CPU architecture" {
+			value = strings.TrimSpace(value)
+			if value != "" && value[0] >= '5' && value[0] <= '8' {
+				return int(value[0] - '0')
+			}
+		}
+	}
+
+	return 0
+}
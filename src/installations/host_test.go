@@ -0,0 +1,79 @@
+package installations
+
+import "testing"
+
+func TestDetectAMD64Level(t *testing.T) {
+	v2 := "fpu sse sse2 cx16 lahf_lm popcnt sse4_1 sse4_2 ssse3"
+	v3 := v2 + " avx avx2 bmi1 bmi2 f16c fma abm movbe xsave"
+	v4 := v3 + " avx512f avx512bw avx512cd avx512dq avx512vl"
+
+	tests := []struct {
+		name    string
+		cpuinfo string
+		level   int
+	}{
+		{name: "no cpuinfo", cpuinfo: "", level: 0},
+		{name: "no flags", cpuinfo: "processor\t: 0\nmodel name\t: Some CPU\n", level: 0},
+		{name: "baseline", cpuinfo: "flags\t\t: fpu sse sse2\n", level: 1},
+		{name: "v2", cpuinfo: "flags\t\t: " + v2 + "\n", level: 2},
+		{name: "v3", cpuinfo: "processor\t: 0\nflags\t\t: " + v3 + "\nbugs\t\t: spectre_v1\n", level: 3},
+		{name: "v4", cpuinfo: "flags\t\t: " + v4 + "\n", level: 4},
+		{name: "v3 but one flag", cpuinfo: "flags\t\t: " + v2 + " avx avx2 bmi1 bmi2 f16c fma abm xsave\n", level: 2},
+		{name: "v4 flags without v2", cpuinfo: "flags\t\t: fpu sse sse2 avx512f avx512bw avx512cd avx512dq avx512vl\n", level: 1},
+		{name: "first processor", cpuinfo: "flags\t\t: " + v3 + "\n\nflags\t\t: fpu\n", level: 3},
+	}
+
+	for _, tt := range tests {
+		if level := detectAMD64Level(tt.cpuinfo); level != tt.level {
+			t.Errorf("%s: expected level %d, got %d", tt.name, tt.level, level)
+		}
+	}
+}
+
+func TestLibcOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		libraries []string
+		lddOutput string
+		libc      string
+	}{
+		{name: "nothing found", libc: ""},
+		{name: "musl loader", libraries: []string{"/lib/ld-musl-x86_64.so.1"}, libc: "musl"},
+		{name: "musl loader next to glibc", libraries: []string{"/lib/ld-musl-aarch64.so.1", "/lib/libc.so.6"}, libc: "musl"},
+		{name: "musl ldd", lddOutput: "musl libc (x86_64)\nVersion 1.2.4\n", libc: "musl"},
+		{name: "glibc ldd", lddOutput: "ldd (Ubuntu GLIBC 2.35-0ubuntu3.1) 2.35\n", libc: "gnu"},
+		{name: "gnu libc ldd", lddOutput: "ldd (GNU libc) 2.38\n", libc: "gnu"},
+		{name: "multiarch libc", libraries: []string{"/lib/x86_64-linux-gnu/libc.so.6"}, libc: "gnu"},
+		{name: "lib64 libc", libraries: []string{"/lib64/libc.so.6"}, lddOutput: "ldd: command not found", libc: "gnu"},
+		{name: "unknown ldd", lddOutput: "ldd: command not found", libc: ""},
+	}
+
+	for _, tt := range tests {
+		if libc := libcOf(tt.libraries, tt.lddOutput); libc != tt.libc {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.libc, libc)
+		}
+	}
+}
+
+func TestARMVariantOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		machine string
+		cpuinfo string
+		variant int
+	}{
+		{name: "armv6l", machine: "armv6l\n", variant: 6},
+		{name: "armv7l", machine: "armv7l\n", variant: 7},
+		{name: "machine wins over cpuinfo", machine: "armv7l\n", cpuinfo: "CPU architecture: 8\n", variant: 7},
+		{name: "cpuinfo", machine: "arm\n", cpuinfo: "processor\t: 0\nCPU architecture: 7\n", variant: 7},
+		{name: "no uname", cpuinfo: "CPU architecture: 6\n", variant: 6},
+		{name: "unknown architecture", cpuinfo: "CPU architecture: AArch64\n", variant: 0},
+		{name: "nothing known", machine: "aarch64\n", variant: 0},
+	}
+
+	for _, tt := range tests {
+		if variant := armVariantOf(tt.machine, tt.cpuinfo); variant != tt.variant {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.variant, variant)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package installations
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -22,78 +23,69 @@ import (
 	"github.com/ricardofabila/fox/src/utils"
 )
 
-var installationsPath = constants.FoxRootPath + "installations.yaml"
-
-func LoadInstallations() types.Installations {
-	file, err := os.OpenFile(installationsPath,
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		color.Red("Error writing installations file at "+installationsPath+": %s", err.Error())
-		os.Exit(1)
-	}
-
-	err = file.Close()
-	if err != nil {
-		color.Red("Error closing installations file at "+installationsPath+"installations.yaml: %s", err.Error())
-		os.Exit(1)
-	}
+func installationsPath() string {
+	return constants.FoxRootPath + "installations.yaml"
+}
 
-	data, err := os.ReadFile(installationsPath)
-	if err != nil {
-		color.Red("Error reading installations file at "+installationsPath+"installations.yaml: %s", err.Error())
-		os.Exit(1)
-	}
-	if len(data) == 0 {
-		SaveInstallations(types.Installations{})
+// LoadInstallations reads installations.yaml, creating it when it doesn't exist
+func LoadInstallations() (types.Installations, error) {
+	var installations types.Installations
+	if !utils.FileExists(installationsPath()) {
+		return installations, SaveInstallations(installations)
 	}
 
-	data, err = os.ReadFile(installationsPath)
+	data, err := os.ReadFile(installationsPath())
 	if err != nil {
-		color.Red("Error reading installations file at "+installationsPath+"installations.yaml: %s", err.Error())
-		os.Exit(1)
+		return installations, fmt.Errorf("Error reading the installations file at "+installationsPath()+": %w", err)
 	}
 
-	var installations types.Installations
 	err = yaml.Unmarshal(data, &installations)
 	if err != nil {
-		color.Red("Error reading installations file at "+installationsPath+"installations.yaml: %s", err.Error())
-		os.Exit(1)
+		return installations, utils.Classify(utils.ErrStateCorruption, fmt.Errorf("Error reading the installations file at "+installationsPath()+": %w", err))
 	}
 
-	return installations
+	return installations, nil
 }
 
-func SaveInstallations(installations types.Installations) {
+func SaveInstallations(installations types.Installations) error {
 	data, err := yaml.Marshal(&installations)
 	if err != nil {
-		color.Red("Error marshalling installations.yaml: %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Error marshalling installations.yaml: %w", err)
 	}
 
-	err = os.WriteFile(installationsPath, data, 0666)
+	err = os.WriteFile(installationsPath(), data, 0666)
 	if err != nil {
-		color.Red("Error writing installations file at "+installationsPath+": %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Error writing the installations file at "+installationsPath()+": %w", err)
 	}
+
+	return nil
 }
 
-func SaveInstallation(installation types.Installation) {
-	installs := LoadInstallations()
+func SaveInstallation(installation types.Installation) error {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return err
+	}
+
 	// remove duplicate if exist
 	cleanInstalls := lo.Filter[types.Installation](installs.Installations, func(ins types.Installation, _ int) bool {
 		return ins.RealName != installation.RealName
 	})
 
-	SaveInstallations(types.Installations{Installations: append(cleanInstalls, installation)})
+	return SaveInstallations(types.Installations{Installations: append(cleanInstalls, installation)})
 }
 
-func DeleteInstallation(installation types.Installation) {
-	installs := LoadInstallations()
+func DeleteInstallation(installation types.Installation) error {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return err
+	}
+
 	cleanInstalls := lo.Filter[types.Installation](installs.Installations, func(ins types.Installation, _ int) bool {
 		return ins.RealName != installation.RealName
 	})
 
-	SaveInstallations(types.Installations{Installations: cleanInstalls})
+	return SaveInstallations(types.Installations{Installations: cleanInstalls})
 }
 
 func FindPackage(packages []repositoriesTypes.Package, installation types.Installation) *repositoriesTypes.Package {
@@ -108,26 +100,81 @@ func FindPackage(packages []repositoriesTypes.Package, installation types.Instal
 	return nil
 }
 
-func FindInstallation(executableNameOrAlias string) *types.Installation {
-	installs := LoadInstallations()
+func FindInstallation(executableNameOrAlias string) (*types.Installation, error) {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
 	install, found := lo.Find(installs.Installations, func(installation types.Installation) bool {
 		return executableNameOrAlias == installation.RealName
 	})
 
 	if found {
-		return &install
+		return &install, nil
+	}
+
+	return nil, nil
+}
+
+// FindExecutableOwner returns the installation that installed the given extra executable
+func FindExecutableOwner(executableName string) (*types.Installation, error) {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
+	install, found := lo.Find(installs.Installations, func(installation types.Installation) bool {
+		return lo.ContainsBy(installation.Executables, func(e types.InstalledExecutable) bool {
+			return e.Name == executableName
+		})
+	})
+
+	if found {
+		return &install, nil
+	}
+
+	return nil, nil
+}
+
+// RemoveExecutables removes the extra executables of an installation that no other installation uses
+func RemoveExecutables(installation types.Installation) error {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range installation.Executables {
+		sharedWith := lo.Filter(installs.Installations, func(i types.Installation, _ int) bool {
+			return i.RealName != installation.RealName && lo.ContainsBy(i.Executables, func(x types.InstalledExecutable) bool {
+				return x.Name == e.Name
+			})
+		})
+
+		if len(sharedWith) > 0 {
+			continue
+		}
+
+		err := utils.RemoveFile(constants.FoxBinPath + e.Name)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func FindInstallations(executableName string) []types.Installation {
-	installs := LoadInstallations()
+func FindInstallations(executableName string) ([]types.Installation, error) {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return nil, err
+	}
+
 	installations := lo.Filter[types.Installation](installs.Installations, func(t types.Installation, _ int) bool {
 		return t.ExecutableName == executableName
 	})
 
-	return installations
+	return installations, nil
 }
 
 func NotifyNewVersions(packages []repositoriesTypes.Package, installations types.Installations) {
@@ -150,7 +197,7 @@ func GetUpgradable(packages []repositoriesTypes.Package, installations types.Ins
 	var upgradable []repositoriesTypes.Package
 
 	canBeUpgraded := lo.Filter(installations.Installations, func(i types.Installation, _ int) bool {
-		return i.Alias == ""
+		return !i.IsPinned()
 	})
 
 	for _, installation := range canBeUpgraded {
@@ -167,17 +214,60 @@ func GetUpgradable(packages []repositoriesTypes.Package, installations types.Ins
 		}
 	}
 
-	return upgradable
+	// a package can have several installations, eg: with aliases
+	return lo.UniqBy(upgradable, func(pkg repositoriesTypes.Package) string {
+		return pkg.NameWithOwner
+	})
+}
+
+// InstallOptions are the ways a package can be installed
+type InstallOptions struct {
+	// Alias is the name to install the package as, instead of its executable name
+	Alias       string
+	Interactive bool
+	UserConfig  types.UserConfig
+	// InstallFox allows installing fox itself, only 'fox upgrade fox' should set it
+	InstallFox bool
+	// Force the installation even if the package is already at the latest version
+	Force bool
+	// Explain prints the ranked assets of the release and why each one was, or wasn't, chosen
+	Explain bool
+	// NoHooks skips the hooks of the package
+	NoHooks bool
+	// Env and Args make fox install a wrapper that runs the executable with them, on top of the ones of the package
+	// and the previous installation. An empty variable removes it, and an empty argument removes them all, see wrapperSettings
+	Env  map[string]string
+	Args []string
+	// Pinned pins the installation, reinstalling a pinned one keeps the pin either way
+	Pinned bool
+	// Context cancels the installation, nil never does
+	Context context.Context
+}
+
+func (o InstallOptions) context() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+
+	return o.Context
+}
+
+// InstallResult is what InstallPackage did
+type InstallResult struct {
+	Installation types.Installation
+	// UpToDate is true when the package was already installed at the latest version, and nothing changed
+	UpToDate bool
 }
 
-func InstallPackage(availablePackages []repositoriesTypes.Package, executableName, alias string, interactive bool, userConfig types.UserConfig, installFox, force bool) error {
+// InstallPackage installs the package, executableName can have a version, eg: tool@v1.0.0
+func InstallPackage(availablePackages []repositoriesTypes.Package, executableName string, options InstallOptions) (InstallResult, error) {
 	pkgParam := strings.Split(executableName, "@")
 	pkgName := strings.TrimSpace(pkgParam[0])
-	alias = strings.TrimSpace(alias)
+	alias := strings.TrimSpace(options.Alias)
 	version := ""
 
 	if len(pkgParam) != 1 && len(pkgParam) != 2 {
-		return fmt.Errorf("Error. The package name must follow the format: <package_name>@<version>. Given: " + executableName)
+		return InstallResult{}, fmt.Errorf("Error. The package name must follow the format: <package_name>@<version>. Given: " + executableName)
 	}
 
 	if len(pkgParam) == 2 {
@@ -186,27 +276,37 @@ func InstallPackage(availablePackages []repositoriesTypes.Package, executableNam
 		version = "latest"
 	}
 
-	if !installFox && (strings.EqualFold(pkgName, "fox") || strings.EqualFold(alias, "fox")) {
-		return fmt.Errorf("error I cannot install a package with the name of fox.\nThat would kill me o(╥﹏╥)o\nIf you want to upgrade fox run 'fox upgrade fox'")
+	if !options.InstallFox && (strings.EqualFold(pkgName, "fox") || strings.EqualFold(alias, "fox")) {
+		return InstallResult{}, fmt.Errorf("error I cannot install a package with the name of fox.\nThat would kill me o(╥﹏╥)o\nIf you want to upgrade fox run 'fox upgrade fox'")
 	}
 
 	// check for conflicts with packages already installed by other sources
+	installs, err := LoadInstallations()
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	isInstalled := func(realName string) bool {
+		return lo.ContainsBy(installs.Installations, func(i types.Installation) bool {
+			return i.RealName == realName
+		})
+	}
+
 	conflictAlias := utils.IsOnPath(alias)
-	if conflictAlias != "" && alias != "" && !installFox {
-		if FindInstallation(alias) == nil {
-			return fmt.Errorf("The package you want to install with that alias conflicts with: " + conflictAlias)
+	if conflictAlias != "" && alias != "" && !options.InstallFox {
+		if !isInstalled(alias) {
+			return InstallResult{}, fmt.Errorf("The package you want to install with that alias conflicts with: " + conflictAlias)
 		}
 	}
 
 	conflictPkgName := utils.IsOnPath(pkgName)
-	if conflictPkgName != "" && !installFox {
-		if FindInstallation(pkgName) == nil {
-			return fmt.Errorf("The package you want to install conflicts with: " + conflictPkgName)
+	if conflictPkgName != "" && !options.InstallFox {
+		if !isInstalled(pkgName) {
+			return InstallResult{}, fmt.Errorf("The package you want to install conflicts with: " + conflictPkgName)
 		}
 	}
 
-	if userConfig.NotifyOutdatedVersions && interactive {
-		installs := LoadInstallations()
+	if options.UserConfig.NotifyOutdatedVersions && options.Interactive {
 		NotifyNewVersions(availablePackages, installs)
 	}
 
@@ -219,98 +319,181 @@ func InstallPackage(availablePackages []repositoriesTypes.Package, executableNam
 	}
 
 	if pkg == nil {
-		return fmt.Errorf(fmt.Sprintf("Could not find the package '%s'. Try running 'fox update' first.", pkgName))
+		return InstallResult{}, utils.Classify(utils.ErrNotFound, fmt.Errorf(fmt.Sprintf("Could not find the package '%s'. Try running 'fox update' first.", pkgName)))
+	}
+
+	// the extra executables can be shared by installations of the same package, but nothing else
+	for _, e := range pkg.Executables {
+		owner, err := FindExecutableOwner(e.InstallName())
+		if err != nil {
+			return InstallResult{}, err
+		}
+
+		if owner != nil && owner.Package != pkg.NameWithOwner {
+			return InstallResult{}, fmt.Errorf("The executable '%s' of the package you want to install conflicts with the one from: %s", e.InstallName(), owner.Package)
+		}
+
+		conflict := utils.IsOnPath(e.InstallName())
+		if owner == nil && conflict != "" && !options.InstallFox {
+			return InstallResult{}, fmt.Errorf("The executable '%s' of the package you want to install conflicts with: %s", e.InstallName(), conflict)
+		}
 	}
 
 	// package might already be at the latest version
 	if alias == "" {
-		existingInstallation := FindInstallation(pkgName)
+		existingInstallation, err := FindInstallation(pkgName)
+		if err != nil {
+			return InstallResult{}, err
+		}
+
 		if existingInstallation != nil {
 			if strings.Contains(strings.TrimSpace(pkg.LatestVersion), strings.TrimSpace(existingInstallation.Version)) {
-				if !force {
+				// new environment variables or arguments need the wrapper to be generated again
+				if !options.Force && len(options.Env) == 0 && len(options.Args) == 0 {
 					color.Green(" The package " + pkgName + " is already at the latest version: " + existingInstallation.Version)
-					return nil
+					return InstallResult{Installation: *existingInstallation, UpToDate: true}, nil
 				}
 			}
 		}
 	}
 
-	releases, err := pkg.GetReleases()
+	releases, err := pkg.GetReleases(options.context())
 	if err != nil {
-		return err
+		return InstallResult{}, err
 	}
 
 	if len(releases) == 0 {
-		return fmt.Errorf("Error. The package " + pkgName + " has no releases")
+		return InstallResult{}, utils.Classify(utils.ErrNotFound, fmt.Errorf("Error. The package "+pkgName+" has no releases"))
 	}
 
-	var releaseToInstall *repositoriesTypes.Release
-	if version == "latest" {
-		releaseToInstall = &releases[0]
-	} else {
-		for _, release := range releases {
-			if strings.EqualFold(release.Name, version) {
-				releaseToInstall = &release
-				break
-			}
-		}
+	releaseToInstall := FindRelease(releases, version)
+	if releaseToInstall == nil {
+		return InstallResult{}, utils.Classify(utils.ErrNotFound, fmt.Errorf("error package version not found"))
 	}
 
-	if releaseToInstall == nil {
-		return fmt.Errorf("error package version not found")
+	runHooks := false
+	if !options.NoHooks {
+		runHooks, err = ApproveHooks(*pkg, options.Interactive)
+		if err != nil {
+			return InstallResult{}, err
+		}
 	}
 
 	color.Blue(" Installing: %s@%s", pkg.ExecutableName, version)
-	releaseToInstall.Assets = lo.Filter(releaseToInstall.Assets, func(x repositoriesTypes.Asset, _ int) bool {
-		if strings.Contains(x.Name, "windows") {
-			return false
-		}
+	filterScriptAssets(*pkg, releaseToInstall)
 
-		if strings.HasSuffix(x.Name, ".xz") {
-			return false
-		}
+	extracted, err := DownloadAsset(*pkg, *releaseToInstall, options)
+	if err != nil {
+		return InstallResult{}, err
+	}
 
-		if strings.HasSuffix(x.Name, ".sha256") || strings.HasSuffix(x.Name, ".md5") {
-			return false
-		}
+	// past this point the installation is not stopped halfway
+	err = options.context().Err()
+	if err != nil {
+		_ = extracted.discard(pkg.ExecutableName)
+		return InstallResult{}, err
+	}
 
-		if strings.HasSuffix(x.Name, ".gz") && !strings.HasSuffix(x.Name, ".tar.gz") {
-			return false
+	alias = lo.Ternary(alias == "", pkg.ExecutableName, alias)
+
+	if version != "latest" {
+		// check if there is no previous installation, we can avoid the @
+		if isInstalled(alias) {
+			alias += "@" + releaseToInstall.Tag
 		}
+	}
 
-		// pre-filtering assets mean for a different operating system
-		if strings.Contains(strings.ToLower(runtime.GOOS), "darwin") {
-			if strings.Contains(x.Name, "linux") || strings.Contains(x.Name, "windows") {
-				return false
+	hookEnvironment := HookEnvironment{Package: pkg.ExecutableName, Version: releaseToInstall.Tag, Bin: constants.FoxBinPath + alias}
+	if runHooks {
+		err = RunHook("preInstall", pkg.Hooks.PreInstall, hookEnvironment)
+		if err != nil {
+			if discardErr := extracted.discard(pkg.ExecutableName); discardErr != nil {
+				return InstallResult{}, fmt.Errorf("%w\nError removing the extracted files: %s", err, discardErr)
 			}
+			return InstallResult{}, err
 		}
+	}
 
-		if strings.Contains(strings.ToLower(runtime.GOOS), "linux") {
-			if strings.Contains(x.Name, "darwin") || strings.Contains(x.Name, "osx") || strings.Contains(x.Name, "windows") {
-				return false
-			}
+	// keep what gets replaced, to roll back if the new executables don't work.
+	// A forced reinstallation of the same version replaces the stored executable of the wrapper too
+	binNames := append([]string{alias}, lo.Map(pkg.Executables, func(e repositoriesTypes.Executable, _ int) string {
+		return e.InstallName()
+	})...)
+	// the man pages and completions too, they are only kept once the installation is saved
+	backupPaths := append(lo.Map(binNames, func(name string, _ int) string {
+		return constants.FoxBinPath + name
+	}), StorePathFor(pkg.ExecutableName, releaseToInstall.Tag, alias))
+	backupPaths = append(backupPaths, shareFilePaths(extracted.ShareFiles, *pkg, alias, options.UserConfig)...)
+	backup, err := backupBinaries("./fox-backup-"+pkg.ExecutableName, backupPaths)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	rollback := func(cause error) error {
+		_ = extracted.discard(pkg.ExecutableName)
+		err := backup.restore()
+		if err != nil {
+			return fmt.Errorf("%s\nError rolling back the installation: %s", cause, err)
 		}
 
-		return true
-	})
+		return fmt.Errorf("%s\nThe installation of %s was rolled back", cause, pkg.ExecutableName)
+	}
 
-	assetName, err := DownloadAsset(*pkg, *releaseToInstall, interactive)
+	err = MoveAssetToBin(extracted.Executables[0], alias)
 	if err != nil {
-		return err
+		return InstallResult{}, rollback(err)
 	}
 
-	alias = lo.Ternary(alias == "", pkg.ExecutableName, alias)
+	previous, err := FindInstallation(alias)
+	if err != nil {
+		return InstallResult{}, rollback(err)
+	}
 
-	if version != "latest" {
-		// check if there is no previous installation, we can avoid the @
-		if FindInstallation(alias) != nil {
-			alias += "@" + releaseToInstall.Tag
+	env, args := wrapperSettings(*pkg, options, previous)
+	storePath := ""
+	if len(env) > 0 || len(args) > 0 {
+		storePath = StorePathFor(pkg.ExecutableName, releaseToInstall.Tag, alias)
+		err = WrapExecutable(types.Installation{
+			ExecutableName: pkg.ExecutableName,
+			RealName:       alias,
+			Version:        releaseToInstall.Tag,
+			Env:            env,
+			Args:           args,
+			StorePath:      storePath,
+		})
+		if err != nil {
+			return InstallResult{}, rollback(err)
 		}
 	}
 
-	err = MoveAssetToBin(assetName, alias)
+	var installedExecutables []types.InstalledExecutable
+	for i, e := range pkg.Executables {
+		err = MoveAssetToBin(extracted.Executables[i+1], e.InstallName())
+		if err != nil {
+			return InstallResult{}, rollback(err)
+		}
+
+		installed := types.InstalledExecutable{Name: e.InstallName()}
+		installed.SHA256, installed.Size, err = utils.FileDigest(constants.FoxBinPath + installed.Name)
+		if err != nil {
+			return InstallResult{}, rollback(err)
+		}
+
+		installedExecutables = append(installedExecutables, installed)
+	}
+
+	for _, name := range binNames {
+		// the wrapper is a script fox generated, check what it runs instead
+		path := lo.Ternary(name == alias && storePath != "", storePath, constants.FoxBinPath+name)
+		err = ValidateExecutable(path, CurrentHost())
+		if err != nil {
+			return InstallResult{}, rollback(err)
+		}
+	}
+
+	err = RunPackageTest(*pkg, alias)
 	if err != nil {
-		return err
+		return InstallResult{}, rollback(err)
 	}
 
 	if len(pkg.DependsOn) > 0 {
@@ -318,23 +501,109 @@ func InstallPackage(availablePackages []repositoriesTypes.Package, executableNam
 	}
 
 	if pkg.NameWithOwner == "ricardofabila/fox" {
-		return nil
+		err = backup.keep()
+		if err != nil {
+			return InstallResult{}, err
+		}
+
+		return InstallResult{Installation: types.Installation{Package: pkg.NameWithOwner, ExecutableName: pkg.ExecutableName, RealName: alias, Version: releaseToInstall.Tag}}, nil
 	}
 
-	color.Green(" 🦊 Installed: %s@%s as %s", pkg.ExecutableName, version, alias)
-	// save the installation
+	// save the installation, until then any error rolls it back
 	install := types.Installation{
 		Timestamp:      time.Now().UnixMilli(),
 		Package:        pkg.NameWithOwner,
 		ExecutableName: pkg.ExecutableName,
 		RealName:       alias,
 		Version:        releaseToInstall.Tag,
+		Executables:    installedExecutables,
+		PreUninstall:   strings.TrimSpace(pkg.Hooks.PreUninstall),
+		Env:            env,
+		Args:           args,
+		StorePath:      storePath,
+		// reinstalling keeps the pin
+		Pinned: options.Pinned || (previous != nil && previous.Pinned),
 	}
 	if alias != pkg.ExecutableName {
 		install.Alias = alias
 	}
 
-	SaveInstallation(install)
+	install.SHA256, install.Size, err = utils.FileDigest(constants.FoxBinPath + alias)
+	if err != nil {
+		return InstallResult{}, rollback(err)
+	}
+
+	if install.IsWrapped() {
+		install.StoreSHA256, install.StoreSize, err = utils.FileDigest(storePath)
+		if err != nil {
+			return InstallResult{}, rollback(err)
+		}
+	}
+
+	install.ShareFiles, err = InstallShareFiles(extracted.ShareFiles, pkg.ExecutableName, options.UserConfig)
+	if err != nil {
+		return InstallResult{}, rollback(err)
+	}
+
+	if options.UserConfig.InstallCompletions {
+		generated, err := GenerateCompletions(*pkg, alias)
+		if err != nil {
+			return InstallResult{}, rollback(err)
+		}
+
+		install.ShareFiles = lo.Uniq(append(install.ShareFiles, generated...))
+	}
+
+	err = SaveInstallation(install)
+	if err != nil {
+		return InstallResult{}, rollback(err)
+	}
+
+	color.Green(" 🦊 Installed: %s@%s as %s", pkg.ExecutableName, version, alias)
+	err = backup.keep()
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	if previous != nil {
+		err = removeStaleFiles(*previous, install)
+		if err != nil {
+			color.Yellow(" Warning: %s was installed, but removing the files of the previous version failed: %s", alias, err)
+		}
+	}
+
+	if runHooks {
+		err = RunHook("postInstall", pkg.Hooks.PostInstall, hookEnvironment)
+		if err != nil {
+			color.Yellow(" Warning: %s was installed, but %s", alias, err)
+		}
+	}
+
+	return InstallResult{Installation: install}, nil
+}
+
+// removeStaleFiles removes what the previous installation had and the new one doesn't, left behind otherwise.
+// eg: executables the package no longer ships
+func removeStaleFiles(previous, install types.Installation) error {
+	stale := types.Installation{RealName: previous.RealName, Executables: lo.Filter(previous.Executables, func(e types.InstalledExecutable, _ int) bool {
+		return !lo.ContainsBy(install.Executables, func(i types.InstalledExecutable) bool {
+			return i.Name == e.Name
+		})
+	})}
+	err := RemoveExecutables(stale)
+	if err != nil {
+		return err
+	}
+
+	staleShareFiles := types.Installation{RealName: previous.RealName, ShareFiles: utils.DifferenceStrings(previous.ShareFiles, install.ShareFiles)}
+	err = RemoveShareFiles(staleShareFiles)
+	if err != nil {
+		return err
+	}
+
+	if previous.StorePath != install.StorePath {
+		return RemoveStoredExecutable(previous)
+	}
 
 	return nil
 }
@@ -359,8 +628,138 @@ func MoveAssetToBin(assetName, alias string) error {
 	return nil
 }
 
-func DownloadAsset(pkg repositoriesTypes.Package, release repositoriesTypes.Release, interactive bool) (string, error) {
+// filterScriptAssets drops the assets meant for other operating systems, for scripts.
+// Binaries are ranked by the asset matching engine instead, see RankAssets
+func filterScriptAssets(pkg repositoriesTypes.Package, release *repositoriesTypes.Release) {
+	if pkg.Type != constants.Script {
+		return
+	}
+
+	release.Assets = lo.Filter(release.Assets, func(x repositoriesTypes.Asset, _ int) bool {
+		if strings.Contains(x.Name, "windows") {
+			return false
+		}
+
+		if strings.HasSuffix(x.Name, ".sha256") || strings.HasSuffix(x.Name, ".md5") {
+			return false
+		}
+
+		// pre-filtering assets mean for a different operating system
+		if strings.Contains(strings.ToLower(runtime.GOOS), "darwin") {
+			if strings.Contains(x.Name, "linux") || strings.Contains(x.Name, "windows") {
+				return false
+			}
+		}
+
+		if strings.Contains(strings.ToLower(runtime.GOOS), "linux") {
+			if strings.Contains(x.Name, "darwin") || strings.Contains(x.Name, "osx") || strings.Contains(x.Name, "windows") {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// FindRelease finds the release by its name or tag, or the newest one that is not a prerelease for 'latest'
+func FindRelease(releases []repositoriesTypes.Release, version string) *repositoriesTypes.Release {
+	if len(releases) == 0 {
+		return nil
+	}
+
+	release, found := lo.Find(releases, func(r repositoriesTypes.Release) bool {
+		if version == "latest" {
+			return !r.Prerelease
+		}
+
+		return strings.EqualFold(r.Name, version) || strings.EqualFold(r.Tag, version)
+	})
+	if !found {
+		return nil
+	}
+
+	return &release
+}
+
+// wrapperSettings merges the environment and arguments of the package, the previous installation and the options.
+// The options win over the previous installation, which wins over the package.
+// Variables are merged one by one, and an empty value in the options removes the variable, eg: --env NAME=
+// Arguments replace the ones of lower precedence instead of adding to them, and an empty one removes them all, eg: --arg=
+func wrapperSettings(pkg repositoriesTypes.Package, options InstallOptions, previous *types.Installation) (map[string]string, []string) {
+	env := map[string]string{}
+	args := pkg.Args
+	sources := []map[string]string{pkg.Env}
+	if previous != nil {
+		sources = append(sources, previous.Env)
+		if len(previous.Args) > 0 {
+			args = previous.Args
+		}
+	}
+
+	for _, source := range append(sources, options.Env) {
+		for name, value := range source {
+			env[name] = value
+		}
+	}
+
+	for name, value := range options.Env {
+		if value == "" {
+			delete(env, name)
+		}
+	}
+
+	if len(options.Args) > 0 {
+		args = lo.Filter(options.Args, func(arg string, _ int) bool {
+			return arg != ""
+		})
+	}
+
+	return lo.Ternary(len(env) > 0, env, nil), lo.Ternary(len(args) > 0, args, nil)
+}
+
+// ExtractedAsset are the files taken out of a downloaded asset
+type ExtractedAsset struct {
+	// Executables are in the same order as Package.ExecutableNames()
+	Executables []string
+	// ShareFiles are the man pages and completions found in the archive, if any
+	ShareFiles []ShareFile
+}
+
+// discard removes the extracted files when the installation stops before using them
+func (e ExtractedAsset) discard(executableName string) error {
+	for _, path := range e.Executables {
+		err := utils.RemoveFile(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return utils.RemoveDirectory(shareStagingDirectory(executableName))
+}
+
+// DownloadAsset downloads the right asset of the release and extracts the files of the package from it
+func DownloadAsset(pkg repositoriesTypes.Package, release repositoriesTypes.Release, options InstallOptions) (ExtractedAsset, error) {
+	binaryPath, err := BinaryPath(pkg, release)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
 	if pkg.Type == constants.Script {
+		assetByRule, err := FindAssetByRule(pkg, release)
+		if err != nil {
+			return ExtractedAsset{}, err
+		}
+
+		if assetByRule != nil {
+			color.Magenta(" Fetching the asset " + assetByRule.Name + " of size " + utils.ByteCountIEC(int64(assetByRule.Size)))
+			err = fetchAsset(options.context(), *assetByRule, pkg.NameWithOwner, release, options.UserConfig)
+			if err != nil {
+				return ExtractedAsset{}, err
+			}
+
+			return extractDownloadedAsset(pkg, assetByRule.Name, binaryPath)
+		}
+
 		assetsNames := lo.Map[repositoriesTypes.Asset, string](release.Assets, func(x repositoriesTypes.Asset, _ int) string {
 			return x.Name
 		})
@@ -371,14 +770,14 @@ func DownloadAsset(pkg repositoriesTypes.Package, release repositoriesTypes.Rele
 
 		// if no match, use the zip source code that every release has and extract the script from there
 		if len(ranks) == 0 {
-			_, err := utils.ExecuteCommandAndGetOutput("gh", []string{"release", "download", "--repo", pkg.NameWithOwner, release.Tag, "--archive", "zip", "--dir", "."}...)
+			_, err := utils.ExecuteCommandAndGetOutputContext(options.context(), "gh", []string{"release", "download", "--repo", pkg.NameWithOwner, release.Tag, "--archive", "zip", "--dir", "."}...)
 			if err != nil {
-				return "", err
+				return ExtractedAsset{}, err
 			}
 
 			files, err := ioutil.ReadDir(".")
 			if err != nil {
-				return "", err
+				return ExtractedAsset{}, err
 			}
 
 			var filesInCurrentDirectory []string
@@ -388,17 +787,12 @@ func DownloadAsset(pkg repositoriesTypes.Package, release repositoriesTypes.Rele
 
 			zipRanks := fuzzy.RankFindNormalizedFold(pkg.Name+".zip", filesInCurrentDirectory)
 			if len(zipRanks) == 0 {
-				return "", fmt.Errorf("error finding zip ball")
+				return ExtractedAsset{}, fmt.Errorf("error finding zip ball")
 			}
 			best := lo.MaxBy[fuzzy.Rank](zipRanks, func(rank, max fuzzy.Rank) bool {
 				return rank.Distance > max.Distance
 			})
-			assetName, err := ExtractAsset(best.Target, pkg.ExecutableName)
-			if err != nil {
-				return "", err
-			}
-
-			return assetName, nil
+			return ExtractExecutables(best.Target, pkg.ExecutableNames(), binaryPath)
 		}
 
 		best := lo.MaxBy[fuzzy.Rank](ranks, func(rank, max fuzzy.Rank) bool {
@@ -407,106 +801,109 @@ func DownloadAsset(pkg repositoriesTypes.Package, release repositoriesTypes.Rele
 		assetToDownload := &release.Assets[best.OriginalIndex]
 
 		if assetToDownload == nil {
-			return "", fmt.Errorf("Error. Found no installable asset for the given release: " + pkg.ExecutableName)
+			return ExtractedAsset{}, fmt.Errorf("Error. Found no installable asset for the given release: " + pkg.ExecutableName)
 		}
 
 		color.Magenta(" Fetching the asset " + assetToDownload.Name + " of size " + utils.ByteCountIEC(int64(assetToDownload.Size)))
-		err := assetToDownload.DownloadAsset(pkg.NameWithOwner)
+		err = fetchAsset(options.context(), *assetToDownload, pkg.NameWithOwner, release, options.UserConfig)
 		if err != nil {
-			return "", err
-		}
-
-		if utils.FileHasTarExtension(assetToDownload.Name) || utils.FileHasZIPExtension(assetToDownload.Name) {
-			assetName, err := ExtractAsset(assetToDownload.Name, pkg.ExecutableName)
-			return assetName, err
+			return ExtractedAsset{}, err
 		}
 
-		return assetToDownload.Name, nil
+		return extractDownloadedAsset(pkg, assetToDownload.Name, binaryPath)
 	}
 
 	if pkg.Type == constants.Binary {
-		assetToDownload, err := GetAssetToDownloadForBinary(pkg, release.Assets, interactive)
+		assetToDownload, err := GetAssetToDownloadForBinary(pkg, release, options.Interactive, options.Explain)
 		if err != nil {
-			return "", err
+			return ExtractedAsset{}, err
 		}
 
 		color.Magenta(" Fetching the asset " + assetToDownload.Name + " of size " + utils.ByteCountIEC(int64(assetToDownload.Size)))
-		err = assetToDownload.DownloadAsset(pkg.NameWithOwner)
+		err = fetchAsset(options.context(), *assetToDownload, pkg.NameWithOwner, release, options.UserConfig)
 		if err != nil {
-			return "", err
+			return ExtractedAsset{}, err
 		}
 
-		if utils.FileHasTarExtension(assetToDownload.Name) || utils.FileHasZIPExtension(assetToDownload.Name) {
-			assetName, err := ExtractAsset(assetToDownload.Name, pkg.ExecutableName)
-			return assetName, err
+		return extractDownloadedAsset(pkg, assetToDownload.Name, binaryPath)
+	}
+
+	return ExtractedAsset{}, fmt.Errorf("Error. The following package type is not valid: " + pkg.Type)
+}
+
+// extractDownloadedAsset extracts the executables of the package if the asset is an archive or compressed
+func extractDownloadedAsset(pkg repositoriesTypes.Package, assetName, binaryPath string) (ExtractedAsset, error) {
+	archiveType, err := utils.DetectArchive(assetName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	if archiveType == utils.TarArchive || archiveType == utils.ZIPArchive || archiveType == utils.DebPackage || archiveType == utils.RPMPackage {
+		return ExtractExecutables(assetName, pkg.ExecutableNames(), binaryPath)
+	}
+
+	if len(pkg.Executables) > 0 {
+		return ExtractedAsset{}, fmt.Errorf("Error. The package " + pkg.ExecutableName + " declares multiple executables, but the asset " + assetName + " is not an archive")
+	}
+
+	if binaryPath != "" {
+		color.Yellow(" Warning: the asset %s is not an archive, ignoring the binaryPath '%s' of %s", assetName, binaryPath, pkg.ExecutableName)
+	}
+
+	// eg: tool-linux-amd64.gz
+	if archiveType == utils.CompressedFile {
+		err = utils.DecompressFile(assetName, "./"+pkg.ExecutableName)
+		if err != nil {
+			return ExtractedAsset{}, err
+		}
+
+		err = utils.RemoveFile("./" + assetName)
+		if err != nil {
+			return ExtractedAsset{}, err
 		}
 
-		return assetToDownload.Name, nil
+		return ExtractedAsset{Executables: []string{"./" + pkg.ExecutableName}}, nil
 	}
 
-	return "", fmt.Errorf("Error. The following package type is not valid: " + pkg.Type)
+	return ExtractedAsset{Executables: []string{assetName}}, nil
 }
 
-func GetAssetToDownloadForBinary(pkg repositoriesTypes.Package, assets []repositoriesTypes.Asset, interactive bool) (*repositoriesTypes.Asset, error) {
+func GetAssetToDownloadForBinary(pkg repositoriesTypes.Package, release repositoriesTypes.Release, interactive, explain bool) (*repositoriesTypes.Asset, error) {
+	assets := release.Assets
 	if len(assets) == 0 {
-		return nil, fmt.Errorf("Error. Found no assets for the given release: " + pkg.ExecutableName)
+		return nil, utils.Classify(utils.ErrNotFound, fmt.Errorf("Error. Found no assets for the given release: "+pkg.ExecutableName))
 	}
 
-	var assetToDownload *repositoriesTypes.Asset
-	assetsNames := lo.Map[repositoriesTypes.Asset, string](assets, func(x repositoriesTypes.Asset, _ int) string {
-		return x.Name
-	})
+	// the rules declared by the package take precedence over guessing
+	assetToDownload, err := FindAssetByRule(pkg, release)
+	if err != nil {
+		return nil, err
+	}
 
-	usersRuntime := runtime.GOOS + " " + runtime.GOARCH
-	assetToSearchFor := usersRuntime
-	ranks := fuzzy.RankFindNormalizedFold(assetToSearchFor, assetsNames)
-
-	// Deal with macOS having different names and architectures
-	// (╯°□°）╯︵ ┻━┻
-	if len(ranks) == 0 {
-		// use macos in the case it is darwin, as a lot of packages use that name
-		if strings.Contains(strings.ToLower(usersRuntime), "darwin") {
-			for _, r := range constants.MacOS {
-				assetToSearchFor = r
-				ranks = fuzzy.RankFindNormalizedFold(assetToSearchFor, assetsNames)
-
-				if len(ranks) > 0 {
-					break
-				}
-			}
+	if assetToDownload != nil {
+		if explain {
+			color.Magenta(" Using the asset %s, declared by the package for %s/%s", assetToDownload.Name, runtime.GOOS, runtime.GOARCH)
 		}
 
-		// Deal with linux having different names and architectures
-		// (╯°□°）╯︵ ┻━┻
-		if strings.Contains(strings.ToLower(usersRuntime), "linux") {
-			// just check for linux + x86_64
-			if strings.Contains(strings.ToLower(runtime.GOARCH), "386") || strings.Contains(strings.ToLower(runtime.GOARCH), "amd64") {
-				for _, r := range constants.Linux {
-					assetToSearchFor = r
-					ranks = fuzzy.RankFindNormalizedFold(assetToSearchFor, assetsNames)
-
-					if len(ranks) > 0 {
-						break
-					}
-				}
-			}
-		}
+		return confirmAsset(assetToDownload, interactive)
 	}
 
-	if len(ranks) == 0 {
-		assetToSearchFor = usersRuntime
-		return nil, fmt.Errorf("Error. Found no assets that match your OS and Architecture: " + assetToSearchFor)
+	host := CurrentHost()
+	candidates := RankAssets(assets, pkg, host)
+	if explain {
+		PrintCandidates(candidates, host)
 	}
 
-	best := lo.MaxBy[fuzzy.Rank](ranks, func(rank, max fuzzy.Rank) bool {
-		return rank.Distance > max.Distance
-	})
-	assetToDownload = &assets[best.OriginalIndex]
-
+	assetToDownload = BestAsset(candidates)
 	if assetToDownload == nil {
-		return nil, fmt.Errorf("Error. Found no installable asset for the given release: " + pkg.ExecutableName)
+		return nil, utils.Classify(utils.ErrNotFound, fmt.Errorf("Error. Found no assets that match your OS and Architecture: "+host.OS+" "+host.Arch+"\nRun the installation again with --explain to see why"))
 	}
 
+	return confirmAsset(assetToDownload, interactive)
+}
+
+// confirmAsset asks the user if the found asset is the right one when in interactive mode
+func confirmAsset(assetToDownload *repositoriesTypes.Asset, interactive bool) (*repositoriesTypes.Asset, error) {
 	if interactive {
 		color.Magenta(" Found the asset: " + assetToDownload.Name)
 		prompt := promptui.Select{
@@ -517,14 +914,8 @@ func GetAssetToDownloadForBinary(pkg repositoriesTypes.Package, assets []reposit
 		_, result, err := prompt.Run()
 
 		// most likely a Control+C
-		if err != nil {
-			color.Green(" (Ͼ˳Ͽ)..!!! Aborting installation.")
-			os.Exit(1)
-		}
-
-		if result == "No" {
-			color.Green(" (Ͼ˳Ͽ)..!!! Aborting installation.")
-			os.Exit(1)
+		if err != nil || result == "No" {
+			return nil, utils.Classify(utils.ErrAborted, fmt.Errorf("Aborting installation."))
 		}
 
 		color.Green(" (＾▽＾) Continuing with your installation!")
@@ -534,58 +925,160 @@ func GetAssetToDownloadForBinary(pkg repositoriesTypes.Package, assets []reposit
 }
 
 func ExtractAsset(fileName, executableName string) (string, error) {
-	// if the download was a tar or zip file, process it
-	if !utils.FileHasTarExtension(fileName) && !utils.FileHasZIPExtension(fileName) {
-		return "", fmt.Errorf("the given file was not in a  valid compressed format: " + fileName)
+	extracted, err := ExtractExecutables(fileName, []string{executableName}, "")
+	if err != nil {
+		return "", err
 	}
 
-	directoryName := "./fox-temp-" + executableName
-	err := utils.RemoveDirectory(directoryName)
+	// only packages installed through InstallPackage get their man pages and completions
+	err = utils.RemoveDirectory(shareStagingDirectory(executableName))
 	if err != nil {
 		return "", err
 	}
 
-	utils.CreateDirectoryIfNotExists(directoryName)
+	return extracted.Executables[0], nil
+}
+
+// ExtractExecutables extracts a tar or zip file and moves the given executables out of it into the current directory.
+// When binaryPath is given, it is used as the path of the main executable inside the archive instead of searching for it.
+// Man pages and completions are moved to a staging directory, see ShareFile.
+func ExtractExecutables(fileName string, executableNames []string, binaryPath string) (ExtractedAsset, error) {
+	// if the download was a tar or zip file, process it
+	archiveType, err := utils.DetectArchive(fileName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	if !lo.Contains([]utils.ArchiveType{utils.TarArchive, utils.ZIPArchive, utils.DebPackage, utils.RPMPackage}, archiveType) {
+		return ExtractedAsset{}, fmt.Errorf("the given file was not in a  valid compressed format: " + fileName)
+	}
+
+	directoryName := "./fox-temp-" + executableNames[0]
+	err = utils.RemoveDirectory(directoryName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	err = utils.CreateDirectoryIfNotExists(directoryName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
 
-	if utils.FileHasTarExtension(fileName) {
+	if archiveType == utils.TarArchive {
 		err = utils.ExtractTAR("./"+fileName, directoryName)
 		if err != nil {
-			return "", err
+			return ExtractedAsset{}, err
 		}
 	}
 
-	if utils.FileHasZIPExtension(fileName) {
+	if archiveType == utils.ZIPArchive {
 		err = utils.ExtractZIP(fileName, directoryName)
 		if err != nil {
-			return "", err
+			return ExtractedAsset{}, err
+		}
+	}
+
+	// the package manager is never involved, fox only takes the executables out of the package
+	if archiveType == utils.DebPackage {
+		err = utils.ExtractDeb(fileName, directoryName)
+		if err != nil {
+			return ExtractedAsset{}, err
+		}
+	}
+
+	if archiveType == utils.RPMPackage {
+		err = utils.ExtractRPM(fileName, directoryName)
+		if err != nil {
+			return ExtractedAsset{}, err
 		}
 	}
 
-	// walk the directory until we find the file with the name of the executable
-	desiredFile := ""
-	err = filepath.Walk(directoryName, func(path string, info os.FileInfo, err error) error {
+	var extracted ExtractedAsset
+	for i, executableName := range executableNames {
+		// only the main executable can be named like the archive
+		desiredFile, err := findExecutable(directoryName, fileName, executableName, i == 0)
+		if err != nil {
+			return ExtractedAsset{}, err
+		}
+
+		if i == 0 && binaryPath != "" {
+			desiredFile = filepath.Join(directoryName, binaryPath)
+			if !strings.HasPrefix(desiredFile, filepath.Clean(directoryName)+string(os.PathSeparator)) || !utils.FileExists(desiredFile) {
+				return ExtractedAsset{}, fmt.Errorf("Error. The binaryPath '%s' of %s was not found in %s", binaryPath, executableName, fileName)
+			}
+
+			// eg: usr/bin/tool -> ../lib/tool/tool in Linux packages
+			desiredFile, err = filepath.EvalSymlinks(desiredFile)
+			if err != nil {
+				return ExtractedAsset{}, err
+			}
+		}
+
+		if desiredFile == "" {
+			return ExtractedAsset{}, fmt.Errorf("Error. Found no installable asset for the given releas of: " + executableName)
+		}
+
+		// move file out of the directory
+		err = utils.MoveFile(desiredFile, "./"+executableName)
+		if err != nil {
+			return ExtractedAsset{}, err
+		}
+
+		extracted.Executables = append(extracted.Executables, "./"+executableName)
+	}
+
+	extracted.ShareFiles, err = stageShareFiles(directoryName, executableNames[0])
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	err = utils.RemoveFile("./" + fileName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	// remove dangling directory, we don't need it
+	err = utils.RemoveDirectory(directoryName)
+	if err != nil {
+		return ExtractedAsset{}, err
+	}
+
+	return extracted, nil
+}
+
+// findExecutable walks the directory looking for the file of the executable.
+// An exact name match wins over a match by the archive name, which wins over a partial match.
+func findExecutable(directoryName, fileName, executableName string, matchArchiveName bool) (string, error) {
+	exactMatch := ""
+	archiveMatch := ""
+	partialMatch := ""
+	err := filepath.Walk(directoryName, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if strings.EqualFold(executableName, info.Name()) && !info.IsDir() {
-			desiredFile = path
+		if info.IsDir() {
+			return nil
+		}
+
+		if strings.EqualFold(executableName, info.Name()) {
+			exactMatch = path
 			return nil
 		}
 
 		// some bundlers use the name of the folder as the name of the binary, so match without extension
-		if strings.EqualFold(utils.ZIPWithoutExtension(fileName), info.Name()) && !info.IsDir() {
-			desiredFile = path
+		if matchArchiveName && strings.EqualFold(utils.ZIPWithoutExtension(fileName), info.Name()) {
+			archiveMatch = path
 			return nil
 		}
 
-		if strings.EqualFold(utils.TarWithoutExtension(fileName), info.Name()) && !info.IsDir() {
-			desiredFile = path
+		if matchArchiveName && strings.EqualFold(utils.TarWithoutExtension(fileName), info.Name()) {
+			archiveMatch = path
 			return nil
 		}
 
 		// in the case for scripts, the file might have an extension or something extra. eg: my-script.js
-		if strings.Contains(strings.ToLower(info.Name()), strings.ToLower(executableName)) && !info.IsDir() {
+		if strings.Contains(strings.ToLower(info.Name()), strings.ToLower(executableName)) {
 			// some scripts have man pages that end with a .number
 			match, _ := regexp.MatchString("\\.\\d+", info.Name())
 			if match {
@@ -598,7 +1091,7 @@ func ExtractAsset(fileName, executableName string) (string, error) {
 				}
 			}
 
-			desiredFile = path
+			partialMatch = path
 		}
 
 		return nil
@@ -607,26 +1100,12 @@ func ExtractAsset(fileName, executableName string) (string, error) {
 		return "", err
 	}
 
-	if desiredFile == "" {
-		return "", fmt.Errorf("Error. Found no installable asset for the given releas of: " + executableName)
-	}
-
-	// move file out of the directory
-	err = utils.MoveFile(desiredFile, "./"+executableName)
-	if err != nil {
-		return "", err
-	}
-
-	err = utils.RemoveFile("./" + fileName)
-	if err != nil {
-		return "", err
-	}
-
-	// remove dangling directory, we don't need it
-	err = utils.RemoveDirectory(directoryName)
-	if err != nil {
-		return "", err
+	found := lo.Ternary(exactMatch != "", exactMatch, lo.Ternary(archiveMatch != "", archiveMatch, partialMatch))
+	if found == "" {
+		return "", nil
 	}
 
-	return "./" + executableName, nil
+	// eg: bin/tool -> ../libexec/tool, move the actual file and not the link.
+	// The extraction already made sure links don't point outside the archive
+	return filepath.EvalSymlinks(found)
 }
@@ -0,0 +1,94 @@
+package installations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+func TestWrapperSettings(t *testing.T) {
+	pkg := repositoriesTypes.Package{Env: map[string]string{"A": "package", "B": "package"}, Args: []string{"--package"}}
+	previous := &types.Installation{Env: map[string]string{"B": "previous", "C": "previous"}, Args: []string{"--previous"}}
+
+	tests := []struct {
+		name     string
+		options  InstallOptions
+		previous *types.Installation
+		wantEnv  map[string]string
+		wantArgs []string
+	}{
+		{
+			name:     "only the package",
+			wantEnv:  map[string]string{"A": "package", "B": "package"},
+			wantArgs: []string{"--package"},
+		},
+		{
+			name:     "the previous installation wins over the package",
+			previous: previous,
+			wantEnv:  map[string]string{"A": "package", "B": "previous", "C": "previous"},
+			wantArgs: []string{"--previous"},
+		},
+		{
+			name:     "the options win over the previous installation",
+			options:  InstallOptions{Env: map[string]string{"C": "options"}, Args: []string{"--options"}},
+			previous: previous,
+			wantEnv:  map[string]string{"A": "package", "B": "previous", "C": "options"},
+			wantArgs: []string{"--options"},
+		},
+		{
+			name:     "an empty variable removes it",
+			options:  InstallOptions{Env: map[string]string{"A": "", "C": ""}},
+			previous: previous,
+			wantEnv:  map[string]string{"B": "previous"},
+			wantArgs: []string{"--previous"},
+		},
+		{
+			name:     "an empty argument removes them all",
+			options:  InstallOptions{Args: []string{""}},
+			previous: previous,
+			wantEnv:  map[string]string{"A": "package", "B": "previous", "C": "previous"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, args := wrapperSettings(pkg, tt.options, tt.previous)
+			if !reflect.DeepEqual(env, tt.wantEnv) {
+				t.Errorf("expected the environment %v, got %v", tt.wantEnv, env)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("expected the arguments %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestFindRelease(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "latest", want: "v1.2.0"},
+		{version: "v2.0.0-rc1", want: "v2.0.0-rc1"},
+		{version: "v1.1.0", want: "v1.1.0"},
+		{version: "v3.0.0"},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if release := FindRelease(prereleases, tt.version); release != nil {
+			got = release.Tag
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.version, tt.want, got)
+		}
+	}
+
+	if release := FindRelease(prereleases[:1], "latest"); release != nil {
+		t.Errorf("expected no latest release when there are only prereleases, got %s", release.Tag)
+	}
+}
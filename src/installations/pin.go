@@ -0,0 +1,28 @@
+package installations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ricardofabila/fox/src/types"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// SetPinned pins, or unpins, the installation with the given name, or alias, so upgrades skip it
+func SetPinned(realName string, pinned bool) (types.Installation, error) {
+	install, err := FindInstallation(realName)
+	if err != nil {
+		return types.Installation{}, err
+	}
+
+	if install == nil {
+		return types.Installation{}, utils.Classify(utils.ErrNotFound, fmt.Errorf("Error. No installation found for "+realName))
+	}
+
+	if !pinned && strings.Contains(install.RealName, "@") {
+		return *install, fmt.Errorf("Error. %s is named after its version, it can't be upgraded without renaming it.\nReinstall it with 'fox install %s --as <name>' instead", install.RealName, install.ExecutableName)
+	}
+
+	install.Pinned = pinned
+	return *install, SaveInstallation(*install)
+}
@@ -0,0 +1,37 @@
+package installations
+
+import (
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// AssetFor returns the asset installing the release would download, without downloading it.
+// It is nil for scripts installed from the source code of the release.
+func AssetFor(pkg repositoriesTypes.Package, release repositoriesTypes.Release) (*repositoriesTypes.Asset, error) {
+	if pkg.Type != constants.Script {
+		return GetAssetToDownloadForBinary(pkg, release, false, false)
+	}
+
+	filterScriptAssets(pkg, &release)
+	assetByRule, err := FindAssetByRule(pkg, release)
+	if err != nil || assetByRule != nil {
+		return assetByRule, err
+	}
+
+	// the same as DownloadAsset
+	assetsNames := lo.Map(release.Assets, func(x repositoriesTypes.Asset, _ int) string {
+		return x.Name
+	})
+	ranks := fuzzy.RankFindNormalizedFold(pkg.ExecutableName, assetsNames)
+	if len(ranks) == 0 {
+		return nil, nil
+	}
+
+	best := lo.MaxBy(ranks, func(rank, max fuzzy.Rank) bool {
+		return rank.Distance > max.Distance
+	})
+	return &release.Assets[best.OriginalIndex], nil
+}
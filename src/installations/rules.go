@@ -0,0 +1,129 @@
+package installations
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// AssetTemplateData is what the asset rules and the binaryPath of a package can use in their templates.
+// eg: "tool_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz"
+type AssetTemplateData struct {
+	// Version is the tag without the leading v
+	Version string
+	Tag     string
+	OS      string
+	Arch    string
+}
+
+const regexRulePrefix = "regex:"
+
+func newAssetTemplateData(release repositoriesTypes.Release) AssetTemplateData {
+	return AssetTemplateData{
+		Version: strings.TrimPrefix(release.Tag, "v"),
+		Tag:     release.Tag,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+}
+
+func renderAssetTemplate(text string, data AssetTemplateData) (string, error) {
+	tmpl, err := template.New("asset").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the asset template '%s': %s", text, err)
+	}
+
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("error rendering the asset template '%s': %s", text, err)
+	}
+
+	return out.String(), nil
+}
+
+// assetRule returns the rule declared for this OS and architecture, the most specific one wins
+func assetRule(pkg repositoriesTypes.Package) string {
+	for _, key := range []string{runtime.GOOS + "/" + runtime.GOARCH, runtime.GOOS + "/*", runtime.GOOS, "*/*", "*"} {
+		if rule, ok := pkg.Assets[key]; ok {
+			return strings.TrimSpace(rule)
+		}
+	}
+
+	return ""
+}
+
+// FindAssetByRule returns the asset matching the rule the package declares for this OS and architecture.
+// Rules are globs, or regular expressions when prefixed with 'regex:', and both must match the whole name.
+// It returns nil when there is no rule, or nothing matches it, so the heuristic can take over.
+func FindAssetByRule(pkg repositoriesTypes.Package, release repositoriesTypes.Release) (*repositoriesTypes.Asset, error) {
+	rule := assetRule(pkg)
+	if rule == "" {
+		return nil, nil
+	}
+
+	data := newAssetTemplateData(release)
+	var matches func(name string) bool
+	if strings.HasPrefix(rule, regexRulePrefix) {
+		// the values are quoted so the dots in versions don't match anything
+		quoted := AssetTemplateData{
+			Version: regexp.QuoteMeta(data.Version),
+			Tag:     regexp.QuoteMeta(data.Tag),
+			OS:      regexp.QuoteMeta(data.OS),
+			Arch:    regexp.QuoteMeta(data.Arch),
+		}
+		rendered, err := renderAssetTemplate(strings.TrimPrefix(rule, regexRulePrefix), quoted)
+		if err != nil {
+			return nil, err
+		}
+
+		// like the globs, eg: tool_linux doesn't match tool_linux.sha256
+		expression, err := regexp.Compile("^(?:" + rendered + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("error, the asset rule '%s' of %s is not a valid regular expression: %s", rule, pkg.ExecutableName, err)
+		}
+
+		matches = expression.MatchString
+	} else {
+		rendered, err := renderAssetTemplate(rule, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = path.Match(rendered, ""); err != nil {
+			return nil, fmt.Errorf("error, the asset rule '%s' of %s is not a valid glob: %s", rule, pkg.ExecutableName, err)
+		}
+
+		matches = func(name string) bool {
+			matched, _ := path.Match(rendered, name)
+			return matched
+		}
+	}
+
+	for i := range release.Assets {
+		if matches(release.Assets[i].Name) {
+			return &release.Assets[i], nil
+		}
+	}
+
+	color.Yellow(" Warning: no asset of %s@%s matches the rule '%s', guessing instead", pkg.ExecutableName, release.Tag, rule)
+
+	return nil, nil
+}
+
+// BinaryPath returns where the main executable is inside the release archive, if the package declares it
+func BinaryPath(pkg repositoriesTypes.Package, release repositoriesTypes.Release) (string, error) {
+	if strings.TrimSpace(pkg.BinaryPath) == "" {
+		return "", nil
+	}
+
+	return renderAssetTemplate(strings.TrimSpace(pkg.BinaryPath), newAssetTemplateData(release))
+}
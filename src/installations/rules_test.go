@@ -0,0 +1,42 @@
+package installations
+
+import (
+	"testing"
+
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+)
+
+func TestFindAssetByRule(t *testing.T) {
+	release := repositoriesTypes.Release{Tag: "v1.2.0", Assets: assets("tool_1.2.0_linux.tar.gz.sha256", "tool_1.2.0_linux.tar.gz", "tool-1.2.0-linux")}
+
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{rule: "tool_{{.Version}}_linux.tar.gz", want: "tool_1.2.0_linux.tar.gz"},
+		{rule: "tool_*_linux.tar.gz", want: "tool_1.2.0_linux.tar.gz"},
+		{rule: `regex:tool_{{.Version}}_linux\.tar\.gz`, want: "tool_1.2.0_linux.tar.gz"},
+		{rule: `regex:tool_.*_linux\.tar\.gz`, want: "tool_1.2.0_linux.tar.gz"},
+		{rule: `regex:tool_.*_(linux|darwin)\.tar\.gz`, want: "tool_1.2.0_linux.tar.gz"},
+		{rule: "regex:tool-.*", want: "tool-1.2.0-linux"},
+		{rule: "regex:tool-{{.Version}}"},
+		{rule: "regex:linux"},
+	}
+
+	for _, tt := range tests {
+		pkg := repositoriesTypes.Package{ExecutableName: "tool", Assets: map[string]string{"*": tt.rule}}
+		asset, err := FindAssetByRule(pkg, release)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := ""
+		if asset != nil {
+			got = asset.Name
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.rule, tt.want, got)
+		}
+	}
+}
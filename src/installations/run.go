@@ -0,0 +1,186 @@
+package installations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+func runKey(pkg repositoriesTypes.Package, version string) string {
+	return pkg.NameWithOwner + "@" + version + " " + CurrentHost().String()
+}
+
+// cachedRunExecutable returns the path of the cached executable for the key, if it is still there
+func cachedRunExecutable(key string) string {
+	cache, err := LoadDownloadCache()
+	if err != nil {
+		return ""
+	}
+
+	cached, found := lo.Find(cache.Downloads, func(d types.CachedDownload) bool {
+		return d.RunKey == key
+	})
+	if !found || !utils.FileExists(downloadObjectPath(cached.SHA256)) {
+		return ""
+	}
+
+	_ = touchCached(func(d types.CachedDownload) bool {
+		return d.RunKey == key
+	})
+	return downloadObjectPath(cached.SHA256)
+}
+
+// RunPackage runs a package without installing it. The executable is downloaded with the same asset logic as
+// InstallPackage and kept in the downloads cache, so later runs of the same version don't download it again.
+// It doesn't touch installations.yaml nor constants.FoxBinPath, and only returns if it couldn't run it.
+func RunPackage(availablePackages []repositoriesTypes.Package, executableName string, args []string, options InstallOptions) error {
+	pkgName, version, _ := strings.Cut(executableName, "@")
+	pkgName = strings.TrimSpace(pkgName)
+	version = strings.TrimSpace(version)
+
+	pkg, found := lo.Find(availablePackages, func(p repositoriesTypes.Package) bool {
+		return p.ExecutableName == pkgName
+	})
+	if !found {
+		return utils.Classify(utils.ErrNotFound, fmt.Errorf(fmt.Sprintf("Could not find the package '%s'. Try running 'fox update' first.", pkgName)))
+	}
+
+	// the cache knows the latest version, so running it again doesn't need the network
+	if version == "" || version == "latest" {
+		version = lo.Ternary(strings.TrimSpace(pkg.LatestVersion) != "", strings.TrimSpace(pkg.LatestVersion), "latest")
+	}
+
+	path := cachedRunExecutable(runKey(pkg, version))
+	if path == "" {
+		var err error
+		path, err = downloadRunExecutable(pkg, version, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	env := os.Environ()
+	for name, value := range pkg.Env {
+		env = append(env, name+"="+value)
+	}
+
+	argv := append(append([]string{pkg.ExecutableName}, pkg.Args...), args...)
+
+	return syscall.Exec(path, argv, env)
+}
+
+// downloadRunExecutable downloads the executable of the package to the run cache and returns its path
+func downloadRunExecutable(pkg repositoriesTypes.Package, version string, options InstallOptions) (string, error) {
+	releases, err := pkg.GetReleases(options.context())
+	if err != nil {
+		return "", err
+	}
+
+	release := FindRelease(releases, version)
+	if release == nil {
+		return "", utils.Classify(utils.ErrNotFound, fmt.Errorf("error package version not found"))
+	}
+
+	filterScriptAssets(pkg, release)
+
+	// the asset logic works in the current directory, so give it one to itself
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	temporaryDirectory, err := os.MkdirTemp("", "fox-run-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(temporaryDirectory)
+
+	err = os.Chdir(temporaryDirectory)
+	if err != nil {
+		return "", err
+	}
+	defer os.Chdir(workingDirectory)
+
+	extracted, err := DownloadAsset(pkg, *release, options)
+	if err != nil {
+		return "", err
+	}
+
+	downloaded, err := filepath.Abs(extracted.Executables[0])
+	if err != nil {
+		return "", err
+	}
+
+	err = ValidateExecutable(downloaded, CurrentHost())
+	if err != nil {
+		return "", err
+	}
+
+	digest, size, err := utils.FileDigest(downloaded)
+	if err != nil {
+		return "", err
+	}
+
+	limit := DownloadCacheLimit(options.UserConfig)
+	if size > limit {
+		// it would never be indexed, so it stays out of the objects of the cache
+		return uncachedRunExecutable(downloaded, pkg.ExecutableName)
+	}
+
+	err = utils.CreateDirectoryIfNotExists(filepath.Dir(downloadObjectPath(digest)))
+	if err != nil {
+		return "", err
+	}
+
+	err = utils.MoveFile(downloaded, downloadObjectPath(digest))
+	if err != nil {
+		return "", err
+	}
+
+	err = utils.MakeFileExecutable(downloadObjectPath(digest))
+	if err != nil {
+		return "", err
+	}
+
+	// by what was asked and by the tag, so a name or the tag both find it next time.
+	// Never by 'latest', it would never change again
+	keys := []string{runKey(pkg, release.Tag)}
+	if version != "latest" && version != release.Tag {
+		keys = append(keys, runKey(pkg, version))
+	}
+
+	err = addRunExecutable(pkg, release.Tag, digest, size, keys, limit)
+	if err != nil {
+		return "", err
+	}
+
+	color.Green(" 🦊 Cached %s@%s", pkg.ExecutableName, release.Tag)
+
+	return downloadObjectPath(digest), nil
+}
+
+// uncachedRunExecutable moves an executable the cache can't keep to a temporary directory of its own and returns its path.
+// The process is replaced by the executable, so the system cleans it up with the rest of its temporary files
+func uncachedRunExecutable(downloaded, name string) (string, error) {
+	directory, err := os.MkdirTemp("", "fox-run-")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(directory, name)
+	err = utils.MoveFile(downloaded, path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, utils.MakeFileExecutable(path)
+}
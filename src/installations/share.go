@@ -0,0 +1,292 @@
+package installations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// ShareFile is a man page or a completion file shipped in a release archive
+type ShareFile struct {
+	// Path is where the file was staged after extracting the archive
+	Path string
+	// Directory is where the file gets installed, one of the share paths in constants
+	Directory string
+	// Name is the name the file gets installed as
+	Name string
+}
+
+func (f *ShareFile) IsManPage() bool {
+	return strings.HasPrefix(f.Directory, constants.FoxManPath)
+}
+
+// isWanted tells if the user config installs this kind of file
+func (f *ShareFile) isWanted(userConfig types.UserConfig) bool {
+	return lo.Ternary(f.IsManPage(), userConfig.InstallManPages, userConfig.InstallCompletions)
+}
+
+// a man page is a name followed by its section, eg: tool.1 or tool-config.5.gz
+var manPageRegex = regexp.MustCompile(`^(.+)\.([1-9])(\.gz)?$`)
+
+// eg: man, man1 or manpages
+var manDirectoryRegex = regexp.MustCompile(`^man([1-9]|pages)?$`)
+
+// a version at the end of a name, eg: the v1 of tool-v1.2
+var versionSuffixRegex = regexp.MustCompile(`(^|[-_.])v?[0-9]+$`)
+
+// Shells fox can install completions for, and where they go
+func completionDirectories() map[string]string {
+	return map[string]string{
+		"bash": constants.FoxBashCompletionsPath,
+		"zsh":  constants.FoxZshCompletionsPath,
+		"fish": constants.FoxFishCompletionsPath,
+	}
+}
+
+func shareStagingDirectory(executableName string) string {
+	return "./fox-share-" + executableName
+}
+
+// completionName returns the name each shell expects a completion file for the command to have
+func completionName(shell, command string) string {
+	switch shell {
+	case "zsh":
+		return "_" + command
+	case "fish":
+		return command + ".fish"
+	default:
+		return command
+	}
+}
+
+// inManDirectory tells if any directory of the path is a man directory, eg: share/man/man1
+func inManDirectory(path string) bool {
+	return lo.ContainsBy(strings.Split(filepath.Dir(path), "/"), func(directory string) bool {
+		return manDirectoryRegex.MatchString(directory)
+	})
+}
+
+// classifyShareFile decides if a file from an archive is a man page or a completion and where it goes
+func classifyShareFile(path, executableName string) (ShareFile, bool) {
+	name := filepath.Base(path)
+	lowerPath := strings.ToLower(filepath.ToSlash(path))
+
+	// eg: man/man1/tool.1 or tool.1.gz, but not libsomething.so.1 or tool-v1.2
+	match := manPageRegex.FindStringSubmatch(name)
+	if match != nil && !versionSuffixRegex.MatchString(match[1]) && (inManDirectory(lowerPath) || !strings.Contains(match[1], ".")) {
+		return ShareFile{Path: path, Directory: constants.FoxManPath + "man" + match[2] + "/", Name: name}, true
+	}
+
+	if strings.HasSuffix(name, ".fish") {
+		return ShareFile{Path: path, Directory: constants.FoxFishCompletionsPath, Name: name}, true
+	}
+
+	if !strings.Contains(lowerPath, "complet") && !strings.Contains(lowerPath, "autocomplete") {
+		return ShareFile{}, false
+	}
+
+	// eg: completions/_tool or completions/zsh/tool.zsh
+	if strings.HasPrefix(name, "_") || strings.HasSuffix(name, ".zsh") || strings.Contains(lowerPath, "zsh") {
+		command := strings.TrimSuffix(strings.TrimPrefix(name, "_"), ".zsh")
+		return ShareFile{Path: path, Directory: constants.FoxZshCompletionsPath, Name: completionName("zsh", command)}, true
+	}
+
+	// eg: completions/tool.bash or completions/bash/tool
+	if strings.HasSuffix(name, ".bash") || strings.HasSuffix(name, ".bash-completion") || strings.Contains(lowerPath, "bash") {
+		command := strings.TrimSuffix(strings.TrimSuffix(name, ".bash-completion"), ".bash")
+		return ShareFile{Path: path, Directory: constants.FoxBashCompletionsPath, Name: completionName("bash", command)}, true
+	}
+
+	// a completions folder without a hint of the shell, assume bash which is what most tools ship,
+	// but only for a file named like the executable, not a LICENSE or README next to it
+	if name == executableName || name == executableName+".sh" {
+		return ShareFile{Path: path, Directory: constants.FoxBashCompletionsPath, Name: completionName("bash", strings.TrimSuffix(name, ".sh"))}, true
+	}
+
+	return ShareFile{}, false
+}
+
+// stageShareFiles moves the man pages and completions out of the extracted archive before it gets deleted
+func stageShareFiles(directoryName, executableName string) ([]ShareFile, error) {
+	stagingDirectory := shareStagingDirectory(executableName)
+	err := utils.RemoveDirectory(stagingDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ShareFile
+	err = filepath.Walk(directoryName, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		shareFile, ok := classifyShareFile(strings.TrimPrefix(path, filepath.Clean(directoryName)), executableName)
+		if ok {
+			shareFile.Path = path
+			found = append(found, shareFile)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	err = utils.CreateDirectoryIfNotExists(stagingDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var staged []ShareFile
+	for i, f := range found {
+		// avoid duplicates, the same file can be shipped twice in different folders
+		if lo.ContainsBy(staged, func(s ShareFile) bool {
+			return s.Directory == f.Directory && s.Name == f.Name
+		}) {
+			continue
+		}
+
+		stagedPath := filepath.Join(stagingDirectory, fmt.Sprintf("%d-%s", i, f.Name))
+		err = utils.MoveFile(f.Path, stagedPath)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Path = stagedPath
+		staged = append(staged, f)
+	}
+
+	return staged, nil
+}
+
+// InstallShareFiles installs the staged man pages and completions according to the user config.
+// It returns the paths of the installed files.
+func InstallShareFiles(shareFiles []ShareFile, executableName string, userConfig types.UserConfig) ([]string, error) {
+	var installed []string
+	for _, f := range shareFiles {
+		if !f.isWanted(userConfig) {
+			continue
+		}
+
+		err := utils.CreateDirectoryIfNotExists(f.Directory)
+		if err != nil {
+			return installed, err
+		}
+
+		err = utils.MoveFile(f.Path, f.Directory+f.Name)
+		if err != nil {
+			return installed, err
+		}
+
+		installed = append(installed, f.Directory+f.Name)
+	}
+
+	return installed, utils.RemoveDirectory(shareStagingDirectory(executableName))
+}
+
+// shareFilePaths returns the paths InstallShareFiles and GenerateCompletions can write for the installation
+func shareFilePaths(shareFiles []ShareFile, pkg repositoriesTypes.Package, realName string, userConfig types.UserConfig) []string {
+	var paths []string
+	for _, f := range shareFiles {
+		if f.isWanted(userConfig) {
+			paths = append(paths, f.Directory+f.Name)
+		}
+	}
+
+	if userConfig.InstallCompletions && strings.TrimSpace(pkg.CompletionsCommand) != "" {
+		for shell, directory := range completionDirectories() {
+			paths = append(paths, directory+completionName(shell, realName))
+		}
+	}
+
+	return lo.Uniq(paths)
+}
+
+// GenerateCompletions runs the completions command declared by the package for every supported shell.
+// It returns the paths of the generated files.
+func GenerateCompletions(pkg repositoriesTypes.Package, realName string) ([]string, error) {
+	command := strings.Fields(pkg.CompletionsCommand)
+	if len(command) == 0 {
+		return nil, nil
+	}
+
+	var generated []string
+	directories := completionDirectories()
+	for _, shell := range lo.Keys(directories) {
+		directory := directories[shell]
+		// only stdout, anything printed to stderr would end up in the completion script
+		ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+		out, err := exec.CommandContext(ctx, constants.FoxBinPath+realName, append(command, shell)...).Output()
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancel()
+		if timedOut {
+			color.Yellow(" Warning: the %s completions command of %s did not finish after %s", shell, realName, TestTimeout)
+			continue
+		}
+
+		if err != nil || strings.TrimSpace(string(out)) == "" {
+			color.Yellow(" Warning: could not generate %s completions for %s", shell, realName)
+			continue
+		}
+
+		err = utils.CreateDirectoryIfNotExists(directory)
+		if err != nil {
+			return generated, err
+		}
+
+		path := directory + completionName(shell, realName)
+		err = os.WriteFile(path, out, 0666)
+		if err != nil {
+			return generated, err
+		}
+
+		generated = append(generated, path)
+	}
+
+	return generated, nil
+}
+
+// RemoveShareFiles removes the man pages and completions of an installation that no other installation uses
+func RemoveShareFiles(installation types.Installation) error {
+	installs, err := LoadInstallations()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range installation.ShareFiles {
+		shared := lo.ContainsBy(installs.Installations, func(i types.Installation) bool {
+			return i.RealName != installation.RealName && lo.Contains(i.ShareFiles, path)
+		})
+
+		if shared {
+			continue
+		}
+
+		err := utils.RemoveFile(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package installations
+
+import (
+	"testing"
+
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+func TestClassifyManPage(t *testing.T) {
+	tests := []struct {
+		path      string
+		directory string
+	}{
+		{path: "tool.1", directory: constants.FoxManPath + "man1/"},
+		{path: "tool.1.gz", directory: constants.FoxManPath + "man1/"},
+		{path: "share/man/man5/tool-config.5", directory: constants.FoxManPath + "man5/"},
+		{path: "man/tool.conf.5", directory: constants.FoxManPath + "man5/"},
+		{path: "sha256sum.1", directory: constants.FoxManPath + "man1/"},
+		{path: "tool-v1.2"},
+		{path: "tool_1.2.gz"},
+		{path: "man/tool-1.2"},
+		{path: "lib/libtool.so.1"},
+		{path: "tool-manager.so.1"},
+		{path: "tool.10"},
+	}
+
+	for _, tt := range tests {
+		shareFile, ok := classifyShareFile(tt.path, "tool")
+		if tt.directory == "" && ok && shareFile.IsManPage() {
+			t.Errorf("%s: expected it not to be a man page, got %s", tt.path, shareFile.Directory)
+		}
+
+		if tt.directory != "" && (!ok || shareFile.Directory != tt.directory) {
+			t.Errorf("%s: expected a man page in %s, got %q", tt.path, tt.directory, shareFile.Directory)
+		}
+	}
+}
+
+func TestClassifyCompletion(t *testing.T) {
+	tests := []struct {
+		path      string
+		directory string
+		name      string
+	}{
+		{path: "completions/tool.bash", directory: constants.FoxBashCompletionsPath, name: "tool"},
+		{path: "completions/bash/tool", directory: constants.FoxBashCompletionsPath, name: "tool"},
+		{path: "completions/tool", directory: constants.FoxBashCompletionsPath, name: "tool"},
+		{path: "completions/tool.sh", directory: constants.FoxBashCompletionsPath, name: "tool"},
+		{path: "completions/_tool", directory: constants.FoxZshCompletionsPath, name: "_tool"},
+		{path: "completions/zsh/tool.zsh", directory: constants.FoxZshCompletionsPath, name: "_tool"},
+		{path: "tool.fish", directory: constants.FoxFishCompletionsPath, name: "tool.fish"},
+		{path: "completions/LICENSE"},
+		{path: "completions/README"},
+		{path: "completions/other"},
+		{path: "completions/install.sh"},
+		{path: "autocomplete/Makefile"},
+		{path: "bin/tool"},
+	}
+
+	for _, tt := range tests {
+		shareFile, ok := classifyShareFile(tt.path, "tool")
+		if tt.directory == "" {
+			if ok {
+				t.Errorf("%s: expected it not to be a completion, got %s%s", tt.path, shareFile.Directory, shareFile.Name)
+			}
+			continue
+		}
+
+		if !ok || shareFile.Directory != tt.directory || shareFile.Name != tt.name {
+			t.Errorf("%s: expected %s%s, got %q%q", tt.path, tt.directory, tt.name, shareFile.Directory, shareFile.Name)
+		}
+	}
+}
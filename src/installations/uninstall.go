@@ -0,0 +1,66 @@
+package installations
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// UninstallOptions are the ways a package can be uninstalled
+type UninstallOptions struct {
+	// NoHooks skips the preUninstall hook of the package
+	NoHooks bool
+}
+
+// UninstallPackage removes the installation with the given name, or alias, and everything it installed
+func UninstallPackage(realName string, options UninstallOptions) (types.Installation, error) {
+	install, err := FindInstallation(realName)
+	if err != nil {
+		return types.Installation{}, err
+	}
+
+	if install == nil {
+		return types.Installation{}, utils.Classify(utils.ErrNotFound, fmt.Errorf("Error. No installation found for "+realName))
+	}
+
+	if install.PreUninstall != "" && !options.NoHooks {
+		if IsHookApproved(install.Package, install.PreUninstall) {
+			err = RunHook("preUninstall", install.PreUninstall, HookEnvironment{
+				Package: install.ExecutableName,
+				Version: install.Version,
+				Bin:     constants.FoxBinPath + install.RealName,
+			})
+			if err != nil {
+				return *install, fmt.Errorf("%w\nRun with --no-hooks to uninstall it anyway", err)
+			}
+		} else {
+			color.Yellow(" Warning: skipping the preUninstall hook of %s, it was never approved.", realName)
+		}
+	}
+
+	err = utils.RemoveFile(constants.FoxBinPath + install.RealName)
+	if err != nil {
+		return *install, err
+	}
+
+	err = RemoveExecutables(*install)
+	if err != nil {
+		return *install, err
+	}
+
+	err = RemoveShareFiles(*install)
+	if err != nil {
+		return *install, err
+	}
+
+	err = RemoveStoredExecutable(*install)
+	if err != nil {
+		return *install, err
+	}
+
+	return *install, DeleteInstallation(*install)
+}
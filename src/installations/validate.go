@@ -0,0 +1,299 @@
+package installations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// TestTimeout is how long the test command of a package can take
+const TestTimeout = 10 * time.Second
+
+var elfMachines = map[string]elf.Machine{
+	"amd64":   elf.EM_X86_64,
+	"arm64":   elf.EM_AARCH64,
+	"arm":     elf.EM_ARM,
+	"386":     elf.EM_386,
+	"ppc64le": elf.EM_PPC64,
+	"s390x":   elf.EM_S390,
+	"riscv64": elf.EM_RISCV,
+}
+
+var machoCPUs = map[string]macho.Cpu{
+	"amd64": macho.CpuAmd64,
+	"arm64": macho.CpuArm64,
+}
+
+var (
+	elfMagicNumber   = []byte("\x7fELF")
+	peMagicNumber    = []byte("MZ")
+	machoMagicNumber = [][]byte{
+		{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe},
+		{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe},
+		{0xca, 0xfe, 0xba, 0xbe},
+	}
+)
+
+// ValidateExecutable checks the installed file can run on the host: that binaries are built for its OS and
+// architecture, and that the interpreter of scripts is installed. Files fox doesn't recognize are not checked.
+func ValidateExecutable(path string, host Host) error {
+	header := make([]byte, 4)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	n, _ := file.Read(header)
+	_ = file.Close()
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, elfMagicNumber):
+		return validateELF(path, host)
+	case lo.ContainsBy(machoMagicNumber, func(magic []byte) bool { return bytes.HasPrefix(header, magic) }):
+		return validateMachO(path, host)
+	case bytes.HasPrefix(header, peMagicNumber):
+		return fmt.Errorf("%s is a Windows executable", filepath.Base(path))
+	case bytes.HasPrefix(header, []byte("#!")):
+		return validateShebang(path)
+	}
+
+	return nil
+}
+
+func validateELF(path string, host Host) error {
+	name := filepath.Base(path)
+	if host.OS == "darwin" || host.OS == "windows" {
+		return fmt.Errorf("%s is a Linux/Unix executable, it can't run on %s", name, host.OS)
+	}
+
+	file, err := elf.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid executable: %s", name, err)
+	}
+	defer file.Close()
+
+	if host.OS == "linux" && file.OSABI != elf.ELFOSABI_NONE && file.OSABI != elf.ELFOSABI_LINUX {
+		return fmt.Errorf("%s is built for %s, not Linux", name, strings.TrimPrefix(file.OSABI.String(), "ELFOSABI_"))
+	}
+
+	machine, known := elfMachines[host.Arch]
+	if known && file.Machine != machine {
+		return fmt.Errorf("%s is built for %s, not %s", name, strings.TrimPrefix(file.Machine.String(), "EM_"), host.Arch)
+	}
+
+	// eg: a glibc build on Alpine, the loader is missing and running it says "not found"
+	for _, program := range file.Progs {
+		if program.Type != elf.PT_INTERP {
+			continue
+		}
+
+		interpreter, err := bufio.NewReader(program.Open()).ReadString(0)
+		if err != nil && interpreter == "" {
+			return fmt.Errorf("%s is not a valid executable: %s", name, err)
+		}
+
+		interpreter = strings.TrimRight(interpreter, "\x00")
+		if !utils.FileExists(interpreter) {
+			return fmt.Errorf("%s needs %s to run, which your system doesn't have. It is likely built for another C library", name, interpreter)
+		}
+	}
+
+	return nil
+}
+
+func validateMachO(path string, host Host) error {
+	name := filepath.Base(path)
+	if host.OS != "darwin" {
+		return fmt.Errorf("%s is a macOS executable, it can't run on %s", name, host.OS)
+	}
+
+	var cpus []macho.Cpu
+	if fat, err := macho.OpenFat(path); err == nil {
+		defer fat.Close()
+		cpus = lo.Map(fat.Arches, func(a macho.FatArch, _ int) macho.Cpu {
+			return a.Cpu
+		})
+	} else {
+		file, err := macho.Open(path)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid executable: %s", name, err)
+		}
+		defer file.Close()
+		cpus = []macho.Cpu{file.Cpu}
+	}
+
+	cpu, known := machoCPUs[host.Arch]
+	if !known || lo.Contains(cpus, cpu) {
+		return nil
+	}
+
+	// Rosetta 2 runs amd64 binaries on Apple silicon
+	if host.Arch == "arm64" && lo.Contains(cpus, macho.CpuAmd64) {
+		return nil
+	}
+
+	return fmt.Errorf("%s is built for %s, not %s", name, strings.Join(lo.Map(cpus, func(c macho.Cpu, _ int) string {
+		return c.String()
+	}), ", "), host.Arch)
+}
+
+// validateShebang checks the interpreter of a script is installed, eg: #!/usr/bin/env python3
+func validateShebang(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line, _ := bufio.NewReader(file).ReadString('\n')
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s has an empty shebang", filepath.Base(path))
+	}
+
+	interpreter := fields[0]
+	if filepath.Base(interpreter) == "env" {
+		// skip the flags of env, eg: #!/usr/bin/env -S deno run
+		interpreters := lo.Filter(fields[1:], func(f string, _ int) bool {
+			return !strings.HasPrefix(f, "-")
+		})
+		if len(interpreters) == 0 {
+			return nil
+		}
+		interpreter = interpreters[0]
+	}
+
+	if utils.FileExists(interpreter) || utils.IsOnPath(filepath.Base(interpreter)) != "" {
+		return nil
+	}
+
+	return fmt.Errorf("%s needs '%s' to run, install it first", filepath.Base(path), interpreter)
+}
+
+// RunPackageTest runs the test command of the package against the installed executable.
+// The first word of the command is the executable, eg: tool --version
+func RunPackageTest(pkg repositoriesTypes.Package, realName string) error {
+	command := strings.Fields(pkg.Test)
+	if len(command) == 0 {
+		return nil
+	}
+
+	executable := constants.FoxBinPath + command[0]
+	if command[0] == pkg.ExecutableName {
+		executable = constants.FoxBinPath + realName
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, executable, command[1:]...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("the test '%s' did not finish after %s", pkg.Test, TestTimeout)
+	}
+
+	if err != nil {
+		return fmt.Errorf("the test '%s' failed: %s\n%s", pkg.Test, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// binaryBackup keeps the files an installation replaces, so they can be restored if it fails
+type binaryBackup struct {
+	directory string
+	// paths are the files the installation can replace, in constants.FoxBinPath or constants.FoxStorePath
+	paths []string
+	// files maps a path to its backup, only for files that existed
+	files map[string]string
+}
+
+func backupBinaries(directory string, paths []string) (binaryBackup, error) {
+	backup := binaryBackup{directory: directory, paths: paths, files: map[string]string{}}
+	err := utils.RemoveDirectory(directory)
+	if err != nil {
+		return backup, err
+	}
+
+	for i, path := range paths {
+		if !utils.FileExists(path) {
+			continue
+		}
+
+		err = utils.CreateDirectoryIfNotExists(directory)
+		if err != nil {
+			return backup, err
+		}
+
+		// the executable and its wrapper can have the same name
+		backup.files[path] = filepath.Join(directory, fmt.Sprintf("%d-%s", i, filepath.Base(path)))
+		err = utils.MoveFile(path, backup.files[path])
+		if err != nil {
+			return backup, err
+		}
+	}
+
+	return backup, nil
+}
+
+// restore puts the backed up files back in place, and removes the ones that didn't exist before
+func (b *binaryBackup) restore() error {
+	for _, path := range b.paths {
+		err := utils.RemoveFile(path)
+		if err != nil {
+			return err
+		}
+
+		backup, ok := b.files[path]
+		if !ok {
+			removeEmptyStoreDirectories(path)
+			continue
+		}
+
+		err = utils.CreateDirectoryIfNotExists(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		err = utils.MoveFile(backup, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.discard()
+}
+
+// keep puts back the backed up files that nothing replaced, eg: a completion that wasn't generated this time,
+// and drops the rest of the backup
+func (b *binaryBackup) keep() error {
+	for path, backup := range b.files {
+		if utils.FileExists(path) {
+			continue
+		}
+
+		err := utils.MoveFile(backup, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.discard()
+}
+
+func (b *binaryBackup) discard() error {
+	return utils.RemoveDirectory(b.directory)
+}
@@ -0,0 +1,105 @@
+package installations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+// inTempRoot moves the paths of fox to an empty directory for the test
+func inTempRoot(t *testing.T) {
+	t.Helper()
+	previous := constants.FoxRootPath
+	constants.SetRootPath(t.TempDir())
+	t.Cleanup(func() { constants.SetRootPath(previous) })
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBinaryBackupRestore(t *testing.T) {
+	inTempRoot(t)
+	wrapper := constants.FoxBinPath + "tool"
+	stored := StorePathFor("tool", "v1.0.0", "tool")
+	newVersion := StorePathFor("tool", "v2.0.0", "tool")
+	writeFile(t, wrapper, "old wrapper")
+	writeFile(t, stored, "old executable")
+
+	backup, err := backupBinaries(filepath.Join(t.TempDir(), "backup"), []string{wrapper, stored, newVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// what a failed forced reinstallation of both versions leaves behind
+	writeFile(t, wrapper, "new wrapper")
+	writeFile(t, stored, "new executable")
+	writeFile(t, newVersion, "new executable")
+
+	if err = backup.restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, content := range map[string]string{wrapper: "old wrapper", stored: "old executable"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != content {
+			t.Errorf("%s: expected %q, got %q", path, content, data)
+		}
+	}
+
+	if _, err = os.Stat(filepath.Dir(newVersion)); !os.IsNotExist(err) {
+		t.Errorf("the store folder of the new version was not removed: %v", err)
+	}
+}
+
+func TestBinaryBackupKeep(t *testing.T) {
+	inTempRoot(t)
+	executable := constants.FoxBinPath + "tool"
+	bash := constants.FoxBashCompletionsPath + "tool"
+	zsh := constants.FoxZshCompletionsPath + "_tool"
+	writeFile(t, executable, "old executable")
+	writeFile(t, bash, "old bash completions")
+	writeFile(t, zsh, "old zsh completions")
+
+	directory := filepath.Join(t.TempDir(), "backup")
+	backup, err := backupBinaries(directory, []string{executable, bash, zsh})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the zsh completions were not generated this time
+	writeFile(t, executable, "new executable")
+	writeFile(t, bash, "new bash completions")
+
+	if err = backup.keep(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, content := range map[string]string{executable: "new executable", bash: "new bash completions", zsh: "old zsh completions"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != content {
+			t.Errorf("%s: expected %q, got %q", path, content, data)
+		}
+	}
+
+	if _, err = os.Stat(directory); !os.IsNotExist(err) {
+		t.Errorf("the backup was not removed: %v", err)
+	}
+}
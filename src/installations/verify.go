@@ -0,0 +1,195 @@
+package installations
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	repositoriesTypes "github.com/ricardofabila/fox/src/types/repositories"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+// Drift is the difference between installations.yaml and what is actually in constants.FoxBinPath
+type Drift struct {
+	// Missing installations have a record but no file
+	Missing []types.Installation
+	// Modified installations have a file whose checksum differs from the one recorded at install time
+	Modified []types.Installation
+	// Unchecked installations were made before checksums were recorded, so they can't be verified
+	Unchecked []types.Installation
+	// Untracked are files in constants.FoxBinPath that no installation knows about
+	Untracked []string
+}
+
+func (d *Drift) IsClean() bool {
+	return len(d.Missing) == 0 && len(d.Modified) == 0 && len(d.Untracked) == 0
+}
+
+// Broken returns the installations that can be fixed by reinstalling them
+func (d *Drift) Broken() []types.Installation {
+	return append(append([]types.Installation{}, d.Missing...), d.Modified...)
+}
+
+// VerifyInstallations compares every installation against the file on disk
+func VerifyInstallations() (Drift, error) {
+	var drift Drift
+	installs, err := LoadInstallations()
+	if err != nil {
+		return drift, err
+	}
+
+	for _, installation := range installs.Installations {
+		path := constants.FoxBinPath + installation.RealName
+		if !utils.FileExists(path) || (installation.IsWrapped() && !utils.FileExists(installation.StorePath)) {
+			drift.Missing = append(drift.Missing, installation)
+			continue
+		}
+
+		// wrapped installations made before the real executable was recorded can't be fully verified either
+		if installation.SHA256 == "" || (installation.IsWrapped() && installation.StoreSHA256 == "") {
+			drift.Unchecked = append(drift.Unchecked, installation)
+			continue
+		}
+
+		modified, err := isModified(path, installation.SHA256, installation.Size)
+		if err != nil {
+			return drift, err
+		}
+
+		if !modified && installation.IsWrapped() {
+			modified, err = isModified(installation.StorePath, installation.StoreSHA256, installation.StoreSize)
+			if err != nil {
+				return drift, err
+			}
+		}
+
+		if modified {
+			drift.Modified = append(drift.Modified, installation)
+			continue
+		}
+
+		missing, modified, err := verifyExecutables(installation)
+		if err != nil {
+			return drift, err
+		}
+
+		if missing {
+			drift.Missing = append(drift.Missing, installation)
+		} else if modified {
+			drift.Modified = append(drift.Modified, installation)
+		}
+	}
+
+	files, err := os.ReadDir(constants.FoxBinPath)
+	if err != nil {
+		return drift, err
+	}
+
+	tracked := lo.FlatMap(installs.Installations, func(i types.Installation, _ int) []string {
+		return i.Files()
+	})
+
+	for _, file := range files {
+		// fox itself is installed by the install script, not recorded as an installation
+		if file.IsDir() || file.Name() == "fox" {
+			continue
+		}
+
+		if !lo.Contains(tracked, file.Name()) {
+			drift.Untracked = append(drift.Untracked, file.Name())
+		}
+	}
+
+	sort.Strings(drift.Untracked)
+
+	return drift, nil
+}
+
+// verifyExecutables checks the extra executables of an installation
+func verifyExecutables(installation types.Installation) (bool, bool, error) {
+	modified := false
+	for _, e := range installation.Executables {
+		path := constants.FoxBinPath + e.Name
+		if !utils.FileExists(path) {
+			return true, false, nil
+		}
+
+		changed, err := isModified(path, e.SHA256, e.Size)
+		if err != nil {
+			return false, false, err
+		}
+
+		modified = modified || changed
+	}
+
+	return false, modified, nil
+}
+
+// isModified tells if the file is not the one with the digest and size recorded at install time
+func isModified(path, sha256 string, size int64) (bool, error) {
+	digest, actualSize, err := utils.FileDigest(path)
+	if err != nil {
+		return false, err
+	}
+
+	return digest != sha256 || actualSize != size, nil
+}
+
+// RepairInstallation reinstalls the recorded version of a broken installation under the same name.
+// If the reinstallation fails the original file and record are kept, so it still shows up in 'fox verify'.
+func RepairInstallation(availablePackages []repositoriesTypes.Package, installation types.Installation, userConfig types.UserConfig) error {
+	// moved out of the way so the reinstallation doesn't see it as a conflict
+	paths := []string{constants.FoxBinPath + installation.RealName}
+	if installation.IsWrapped() {
+		paths = append(paths, installation.StorePath)
+	}
+
+	backup, err := backupBinaries("./fox-repair-"+installation.RealName, paths)
+	if err != nil {
+		return err
+	}
+
+	restore := func(cause error) error {
+		if err := backup.restore(); err != nil {
+			return fmt.Errorf("%w\nError restoring %s: %s", cause, installation.RealName, err)
+		}
+
+		if err := SaveInstallation(installation); err != nil {
+			return fmt.Errorf("%w\nError restoring the record of %s: %s", cause, installation.RealName, err)
+		}
+
+		return cause
+	}
+
+	// drop the record so the reinstallation doesn't see the broken file as already installed
+	err = DeleteInstallation(installation)
+	if err != nil {
+		return restore(err)
+	}
+
+	_, err = InstallPackage(availablePackages, installation.ExecutableName+"@"+installation.Version, repairOptions(installation, userConfig))
+	if err != nil {
+		return restore(err)
+	}
+
+	return backup.discard()
+}
+
+// repairOptions reinstall the installation as it was: same name, wrapper and pin.
+// Its record is gone by then, so nothing can be taken from the previous installation.
+func repairOptions(installation types.Installation, userConfig types.UserConfig) InstallOptions {
+	return InstallOptions{
+		Alias:      lo.Ternary(installation.RealName != installation.ExecutableName, installation.RealName, ""),
+		UserConfig: userConfig,
+		Force:      true,
+		// the hooks already ran when it was first installed
+		NoHooks: true,
+		Env:     installation.Env,
+		Args:    installation.Args,
+		Pinned:  installation.Pinned,
+	}
+}
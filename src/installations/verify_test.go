@@ -0,0 +1,105 @@
+package installations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+func TestVerifyWrappedInstallation(t *testing.T) {
+	tests := []struct {
+		name         string
+		wrapper      string
+		executable   string
+		wantModified bool
+	}{
+		{name: "untouched", wrapper: "#!/bin/sh", executable: "binary"},
+		{name: "modified wrapper", wrapper: "#!/bin/sh evil", executable: "binary", wantModified: true},
+		{name: "modified executable", wrapper: "#!/bin/sh", executable: "evil", wantModified: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inTempRoot(t)
+			installation := types.Installation{ExecutableName: "tool", RealName: "tool", StorePath: constants.FoxRootPath + "store/tool/v1/tool"}
+			writeFile(t, constants.FoxBinPath+"tool", "#!/bin/sh")
+			writeFile(t, installation.StorePath, "binary")
+
+			var err error
+			installation.SHA256, installation.Size, err = utils.FileDigest(constants.FoxBinPath + "tool")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			installation.StoreSHA256, installation.StoreSize, err = utils.FileDigest(installation.StorePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = SaveInstallation(installation); err != nil {
+				t.Fatal(err)
+			}
+
+			writeFile(t, constants.FoxBinPath+"tool", tt.wrapper)
+			writeFile(t, installation.StorePath, tt.executable)
+			drift, err := VerifyInstallations()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if modified := len(drift.Modified) == 1; modified != tt.wantModified {
+				t.Errorf("expected modified to be %v, got the drift %+v", tt.wantModified, drift)
+			}
+		})
+	}
+}
+
+func TestRepairOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		installation types.Installation
+		wantAlias    string
+		wantPinned   bool
+		wantArgs     []string
+	}{
+		{
+			name:         "plain installation",
+			installation: types.Installation{ExecutableName: "tool", RealName: "tool"},
+		},
+		{
+			name:         "pinned installation",
+			installation: types.Installation{ExecutableName: "tool", RealName: "tool", Pinned: true},
+			wantPinned:   true,
+		},
+		{
+			name:         "aliased and wrapped installation",
+			installation: types.Installation{ExecutableName: "tool", RealName: "tool2", Args: []string{"--verbose"}},
+			wantAlias:    "tool2",
+			wantArgs:     []string{"--verbose"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := repairOptions(tt.installation, types.UserConfig{})
+			if options.Alias != tt.wantAlias {
+				t.Errorf("expected the alias %q, got %q", tt.wantAlias, options.Alias)
+			}
+
+			if options.Pinned != tt.wantPinned {
+				t.Errorf("expected pinned to be %v, got %v", tt.wantPinned, options.Pinned)
+			}
+
+			if !reflect.DeepEqual(options.Args, tt.wantArgs) {
+				t.Errorf("expected the arguments %v, got %v", tt.wantArgs, options.Args)
+			}
+
+			if !options.Force || !options.NoHooks {
+				t.Errorf("expected a forced reinstallation without hooks, got %+v", options)
+			}
+		})
+	}
+}
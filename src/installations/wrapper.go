@@ -0,0 +1,105 @@
+package installations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+	"github.com/ricardofabila/fox/src/utils"
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnv parses K=V pairs, as given to 'fox install --env'
+func ParseEnv(pairs []string) (map[string]string, error) {
+	env := map[string]string{}
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || !envNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("Error. Environment variables must follow the format NAME=value. Given: " + pair)
+		}
+
+		env[name] = value
+	}
+
+	return env, nil
+}
+
+// StorePathFor returns where the real executable of a wrapped installation goes
+func StorePathFor(executableName, version, realName string) string {
+	return constants.FoxStorePath + executableName + "/" + strings.ReplaceAll(version, "/", "_") + "/" + realName
+}
+
+// shellQuote wraps a string in single quotes for sh, escaping the single quotes inside it
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// wrapperScript execs the real executable with the environment and arguments applied
+func wrapperScript(installation types.Installation) string {
+	script := "#!/bin/sh\n"
+	script += fmt.Sprintf("# Generated by fox for %s@%s, see 'fox info %s'\n", installation.ExecutableName, installation.Version, installation.ExecutableName)
+
+	names := lo.Keys(installation.Env)
+	sort.Strings(names)
+	for _, name := range names {
+		script += "export " + name + "=" + shellQuote(installation.Env[name]) + "\n"
+	}
+
+	command := []string{"exec", shellQuote(installation.StorePath)}
+	command = append(command, lo.Map(installation.Args, func(a string, _ int) string {
+		return shellQuote(a)
+	})...)
+	command = append(command, `"$@"`)
+
+	return script + strings.Join(command, " ") + "\n"
+}
+
+// WrapExecutable moves the installed executable to the store and replaces it with a wrapper.
+// The installation must have its Env, Args and StorePath set.
+func WrapExecutable(installation types.Installation) error {
+	err := utils.CreateDirectoryIfNotExists(filepath.Dir(installation.StorePath))
+	if err != nil {
+		return err
+	}
+
+	err = utils.MoveFile(constants.FoxBinPath+installation.RealName, installation.StorePath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(constants.FoxBinPath+installation.RealName, []byte(wrapperScript(installation)), 0755)
+}
+
+// RemoveStoredExecutable removes the real executable of a wrapped installation, and the folders left empty
+func RemoveStoredExecutable(installation types.Installation) error {
+	if !installation.IsWrapped() {
+		return nil
+	}
+
+	err := utils.RemoveFile(installation.StorePath)
+	if err != nil {
+		return err
+	}
+
+	removeEmptyStoreDirectories(installation.StorePath)
+	return nil
+}
+
+// removeEmptyStoreDirectories removes the folders of a stored executable that are left empty
+func removeEmptyStoreDirectories(path string) {
+	// eg: store/tool/v1.0.0/ then store/tool/
+	for directory := filepath.Dir(path); strings.HasPrefix(directory, filepath.Clean(constants.FoxStorePath)+"/"); directory = filepath.Dir(directory) {
+		if os.Remove(directory) != nil {
+			break
+		}
+	}
+}
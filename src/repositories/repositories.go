@@ -40,7 +40,7 @@ func LoadPackagesFromCache(repositoriesConfig repositories.Config, userConfig ty
 
 	err = yaml.Unmarshal(data, &repositoriesStruct)
 	if err != nil {
-		return nil, err
+		return nil, utils.Classify(utils.ErrStateCorruption, fmt.Errorf("Error reading the packages cache at "+constants.CacheFilePath+", run 'fox update': %w", err))
 	}
 
 	return repositoriesStruct.Packages, nil
@@ -65,6 +65,31 @@ func writeDefaultCacheFile() error {
 	return os.WriteFile(constants.CacheFilePath, data, 0666)
 }
 
+// SetInstalledVersions fills the InstalledVersions and Aliases of the packages from the installations
+func SetInstalledVersions(packages []repositories.Package, installs []types.Installation) {
+	for i := range packages {
+		mine := lo.Filter(installs, func(install types.Installation, _ int) bool {
+			return install.ExecutableName == packages[i].ExecutableName
+		})
+
+		// can't naively modify with range
+		packages[i].InstalledVersions = nil
+		packages[i].Aliases = nil
+		if len(mine) == 0 {
+			continue
+		}
+
+		packages[i].InstalledVersions = lo.Map(mine, func(install types.Installation, _ int) string {
+			return install.Version
+		})
+		packages[i].Aliases = lo.Filter(lo.Map(mine, func(install types.Installation, _ int) string {
+			return install.Alias
+		}), func(a string, _ int) bool {
+			return a != ""
+		})
+	}
+}
+
 func UpdatePackagesCache(repositoriesConfig repositories.Config, force bool) error {
 	// create cache file if it doesn't exist
 	if !utils.FileExists(constants.CacheFilePath) {
@@ -90,26 +115,9 @@ func UpdatePackagesCache(repositoriesConfig repositories.Config, force bool) err
 		return err
 	}
 
-	// Load the users custom packages
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	data, err := os.ReadFile(home + constants.RepositoriesFilePath)
-	if err != nil {
-		return err
-	}
-
-	var customPackagesStruct repositories.Config
-	err = yaml.Unmarshal(data, &customPackagesStruct)
-	if err != nil {
-		return err
-	}
-
-	// Hard coding some hidden packages to be able to upgrade them
-	customPackagesStruct.Packages = append(customPackagesStruct.Packages, repositories.HardcodedPackages...)
-	customPackages := LoadPackagesFromRepository(customPackagesStruct.Packages, true)
+	// the custom packages of the config, with some hidden ones hard coded to be able to upgrade them
+	configPackages := append(append(repositories.ConfigPackages{}, repositoriesConfig.Packages...), repositories.HardcodedPackages...)
+	customPackages := LoadPackagesFromRepository(configPackages, true)
 
 	// save the packages file
 	var repositoriesStruct struct {
@@ -118,26 +126,16 @@ func UpdatePackagesCache(repositoriesConfig repositories.Config, force bool) err
 
 	packages = append(packages, customPackages...)
 
-	for i, p := range packages {
-		installs := installations.FindInstallations(p.ExecutableName)
-		if len(installs) > 0 {
-			installed := lo.Map[types.Installation, string](installs, func(i types.Installation, _ int) string {
-				return i.Version
-			})
-			aliases := lo.Map[types.Installation, string](installs, func(i types.Installation, _ int) string {
-				return i.Alias
-			})
-			aliases = lo.Filter(aliases, func(a string, _ int) bool {
-				return a != ""
-			})
-			// can't naively modify with range
-			(&packages[i]).InstalledVersions = installed
-			(&packages[i]).Aliases = aliases
-		}
+	installs, err := installations.LoadInstallations()
+	if err != nil {
+		return err
+	}
 
+	SetInstalledVersions(packages, installs.Installations)
+	for i, p := range packages {
 		// check for conflicts with packages already installed by other sources
 		conflict := utils.IsOnPath(p.ExecutableName)
-		if len(installs) == 0 && conflict != "" {
+		if len(p.InstalledVersions) == 0 && conflict != "" {
 			(&packages[i]).Conflicts = conflict
 		}
 	}
@@ -147,7 +145,7 @@ func UpdatePackagesCache(repositoriesConfig repositories.Config, force bool) err
 	})
 
 	repositoriesStruct.Packages = packages
-	data, err = yaml.Marshal(&repositoriesStruct)
+	data, err := yaml.Marshal(&repositoriesStruct)
 	if err != nil {
 		return err
 	}
@@ -175,6 +173,7 @@ func LoadPackages(repositoriesConfig repositories.Config, verbose bool) ([]repos
 		if err != nil {
 			if verbose {
 				color.Red("Error fetching repositories from remote: " + remote.URL)
+				color.Red(" " + err.Error())
 			}
 
 			continue
@@ -196,18 +195,18 @@ func LoadPackagesFromRemote(remote repositories.Remote, verbose bool) ([]reposit
 		downloadURL, err := utils.ExecuteCommandAndGetOutput("gh", []string{"api", remote.URL, "--jq", ".download_url"}...)
 		downloadURL = strings.TrimSpace(downloadURL)
 		if err != nil {
-			return fetchedPackages, utils.PrintAndReturnError(err.Error())
+			return fetchedPackages, utils.ClassifyGhError(downloadURL, fmt.Errorf("%w: %s", err, downloadURL))
 		}
 
 		b, err = utils.GetFromAPI(downloadURL)
 		if err != nil {
-			return fetchedPackages, utils.PrintAndReturnError(err.Error())
+			return fetchedPackages, err
 		}
 	case "open":
 		temp, err := utils.GetFromAPI(remote.URL)
 		b = temp
 		if err != nil {
-			return fetchedPackages, utils.PrintAndReturnError(err.Error())
+			return fetchedPackages, err
 		}
 	default:
 		return fetchedPackages, fmt.Errorf("error, the remote type '" + remote.Type + "' is not supported. Only 'github' and 'open' are valid values.")
@@ -218,7 +217,7 @@ func LoadPackagesFromRemote(remote repositories.Remote, verbose bool) ([]reposit
 	}
 	err := yaml.Unmarshal(b, &repositoriesStruct)
 	if err != nil {
-		return fetchedPackages, err
+		return fetchedPackages, fmt.Errorf("Error reading the packages of the remote "+remote.URL+": %w", err)
 	}
 	configPackages := repositoriesStruct.Packages
 	if len(configPackages) == 0 {
@@ -285,6 +284,14 @@ func LoadPackagesFromRepository(configPackages repositories.ConfigPackages, verb
 			fetchedPackage.ExecutableName = configPackage.ExecutableName
 			fetchedPackage.Type = configPackage.Type
 			fetchedPackage.DependsOn = configPackage.DependsOn
+			fetchedPackage.Executables = configPackage.Executables
+			fetchedPackage.CompletionsCommand = configPackage.CompletionsCommand
+			fetchedPackage.Assets = configPackage.Assets
+			fetchedPackage.BinaryPath = configPackage.BinaryPath
+			fetchedPackage.Test = configPackage.Test
+			fetchedPackage.Hooks = configPackage.Hooks
+			fetchedPackage.Env = configPackage.Env
+			fetchedPackage.Args = configPackage.Args
 			err = fetchedPackage.SetLatestVersion(verbose)
 			if err != nil {
 				waitGroup.Done()
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/types/repositories"
+)
+
+// SearchFilters narrow down the packages a search returns, empty filters match every package
+type SearchFilters struct {
+	Installed bool
+	Language  string
+	// Owner is the user or organization of the repository, eg: cli in cli/cli
+	Owner string
+}
+
+// SearchResult is a package that matched the query and how well it did, the higher the score the better
+type SearchResult struct {
+	Package repositories.Package
+	Score   int
+}
+
+// How much a match in each field is worth, the executable name is what people usually remember
+const (
+	scoreExactName       = 100
+	scoreNamePrefix      = 80
+	scoreNameContains    = 60
+	scoreNameFuzzy       = 30
+	scoreExactRepo       = 70
+	scoreRepoContains    = 50
+	scoreRepoFuzzy       = 20
+	scoreDescription     = 25
+	scoreDescriptionWord = 8
+	scoreLanguage        = 15
+)
+
+func (f *SearchFilters) matches(p repositories.Package) bool {
+	if f.Installed && len(p.InstalledVersions) == 0 {
+		return false
+	}
+
+	if f.Language != "" && !strings.EqualFold(strings.TrimSpace(f.Language), p.PrimaryLanguage["name"]) {
+		return false
+	}
+
+	owner, _, _ := strings.Cut(p.NameWithOwner, "/")
+	if f.Owner != "" && !strings.EqualFold(strings.TrimSpace(f.Owner), owner) {
+		return false
+	}
+
+	return true
+}
+
+// score ranks a package against a lowercase query, 0 means it doesn't match
+func score(p repositories.Package, query string) int {
+	name := strings.ToLower(p.ExecutableName)
+	_, repo, _ := strings.Cut(strings.ToLower(p.NameWithOwner), "/")
+	description := strings.ToLower(p.Description)
+
+	total := 0
+	switch {
+	case name == query:
+		total += scoreExactName
+	case strings.HasPrefix(name, query):
+		total += scoreNamePrefix
+	case strings.Contains(name, query):
+		total += scoreNameContains
+	case fuzzy.Match(query, name):
+		total += scoreNameFuzzy
+	}
+
+	switch {
+	case repo == query:
+		total += scoreExactRepo
+	case strings.Contains(repo, query):
+		total += scoreRepoContains
+	case fuzzy.Match(query, repo):
+		total += scoreRepoFuzzy
+	}
+
+	if strings.Contains(description, query) {
+		total += scoreDescription
+	}
+
+	// a query of several words can match the description in any order
+	words := strings.Fields(query)
+	if len(words) > 1 {
+		descriptionWords := strings.FieldsFunc(description, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+		})
+		for _, w := range words {
+			if lo.Contains(descriptionWords, w) {
+				total += scoreDescriptionWord
+			}
+		}
+	}
+
+	if strings.EqualFold(p.PrimaryLanguage["name"], query) {
+		total += scoreLanguage
+	}
+
+	return total
+}
+
+// SearchPackages returns the visible packages that match the query and the filters, best matches first.
+// An empty query returns every package that matches the filters, sorted by executable name.
+func SearchPackages(packages []repositories.Package, query string, filters SearchFilters) []SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var results []SearchResult
+	for _, p := range packages {
+		if !p.IsVisible() || !filters.matches(p) {
+			continue
+		}
+
+		if query == "" {
+			results = append(results, SearchResult{Package: p})
+			continue
+		}
+
+		s := score(p, query)
+		if s > 0 {
+			results = append(results, SearchResult{Package: p, Score: s})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+
+		return results[i].Package.ExecutableName < results[j].Package.ExecutableName
+	})
+
+	return results
+}
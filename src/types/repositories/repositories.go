@@ -1,12 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -32,10 +30,60 @@ type Remote struct {
 type ConfigPackages = []ConfigPackage
 
 type ConfigPackage = struct {
-	Path           string   `yaml:"path"`
-	ExecutableName string   `yaml:"executableName"`
-	Type           string   `yaml:"type"`
-	DependsOn      []string `yaml:"dependsOn"`
+	Path           string       `yaml:"path"`
+	ExecutableName string       `yaml:"executableName"`
+	Type           string       `yaml:"type"`
+	DependsOn      []string     `yaml:"dependsOn"`
+	Executables    []Executable `yaml:"executables"`
+	// CompletionsCommand generates completions when run as: <executable> <completionsCommand> <shell>
+	CompletionsCommand string `yaml:"completionsCommand"`
+	// Assets maps os/arch to a glob or 'regex:' template of the asset to download. eg:
+	//   linux/amd64: "tool_{{.Version}}_linux_x86_64.tar.gz"
+	Assets map[string]string `yaml:"assets"`
+	// BinaryPath is the path of the executable inside the archive, it can use the same templates as Assets
+	BinaryPath string `yaml:"binaryPath"`
+	// Test is a command that checks the installed executable works, eg: tool --version
+	Test string `yaml:"test"`
+	// Hooks are shell commands run around the installation, the user approves them the first time
+	Hooks Hooks `yaml:"hooks"`
+	// Env and Args are the default environment variables and arguments the executable runs with
+	Env  map[string]string `yaml:"env"`
+	Args []string          `yaml:"args"`
+}
+
+// Hooks are run with 'sh -c' and only PATH, HOME and the FOX_PACKAGE, FOX_VERSION and FOX_BIN environment variables
+type Hooks struct {
+	PreInstall   string `yaml:"preInstall"`
+	PostInstall  string `yaml:"postInstall"`
+	PreUninstall string `yaml:"preUninstall"`
+}
+
+// Commands returns the hooks that are declared
+func (h *Hooks) Commands() []string {
+	var commands []string
+	for _, c := range []string{h.PreInstall, h.PostInstall, h.PreUninstall} {
+		if strings.TrimSpace(c) != "" {
+			commands = append(commands, strings.TrimSpace(c))
+		}
+	}
+
+	return commands
+}
+
+// Executable is an extra executable shipped in the same release archive as the main one.
+// eg: kubectl-convert next to kubectl
+type Executable struct {
+	Name string `yaml:"name"`
+	// As is the optional name to install the executable as
+	As string `yaml:"as"`
+}
+
+func (e *Executable) InstallName() string {
+	if strings.TrimSpace(e.As) != "" {
+		return strings.TrimSpace(e.As)
+	}
+
+	return strings.TrimSpace(e.Name)
 }
 
 type Package struct {
@@ -49,14 +97,22 @@ type Package struct {
 	// 	"name": "Go"
 	// },
 	// I fetch these separately
-	LatestVersion     string
-	DependsOn         []string
-	ExecutableName    string
-	Type              string
-	Releases          []Release
-	InstalledVersions []string `yaml:"installedVersions"`
-	Aliases           []string `yaml:"aliases"`
-	Conflicts         string
+	LatestVersion      string
+	DependsOn          []string
+	ExecutableName     string
+	Type               string
+	Executables        []Executable
+	CompletionsCommand string
+	Assets             map[string]string
+	BinaryPath         string
+	Test               string
+	Hooks              Hooks
+	Env                map[string]string
+	Args               []string
+	Releases           []Release
+	InstalledVersions  []string `yaml:"installedVersions"`
+	Aliases            []string `yaml:"aliases"`
+	Conflicts          string
 }
 
 // Release represents a GitHub release in a repository.
@@ -71,6 +127,9 @@ type Release struct {
 	Name       string  `json:"name,omitempty"`
 	Type       string  `json:"type,omitempty"`
 	CreatedAt  string  `json:"createdAt,omitempty"`
+	// Body is the release notes, in markdown
+	Body        string `json:"body,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
 }
 
 // Asset represents a GitHub release asset in a repository.
@@ -99,6 +158,16 @@ func (p *Package) IsVisible() bool {
 	return !lo.Contains(constants.DoNotShow, p.ExecutableName)
 }
 
+// ExecutableNames returns the names of all the executables to extract from a release, the main one first
+func (p *Package) ExecutableNames() []string {
+	names := []string{p.ExecutableName}
+	for _, e := range p.Executables {
+		names = append(names, e.Name)
+	}
+
+	return names
+}
+
 func (p *Package) SetLatestVersion(verbose bool) error {
 	// https://docs.github.com/en/rest/releases/releases#get-the-latest-release
 	// gh api /repos/bishopfox/bf/releases/latest --jq ".name"
@@ -122,12 +191,14 @@ func (p *Package) SetLatestVersion(verbose bool) error {
 	return nil
 }
 
-func (p *Package) GetReleases() ([]Release, error) {
+// GetReleases returns the published releases of the package, newest first.
+// Prereleases are kept so they can be browsed and installed by their tag, see Release.Prerelease.
+func (p *Package) GetReleases(ctx context.Context) ([]Release, error) {
 	var releases []Release
-	data, err := utils.ExecuteCommandAndGetOutput("gh", []string{"api", "repos/" + p.NameWithOwner + "/releases"}...)
+	data, err := utils.ExecuteCommandAndGetOutputContext(ctx, "gh", []string{"api", "repos/" + p.NameWithOwner + "/releases"}...)
 	if err != nil {
 		color.Red("%s", data)
-		return releases, utils.PrintAndReturnError(err.Error())
+		return releases, utils.ClassifyGhError(data, utils.PrintAndReturnError(err.Error()))
 	}
 
 	if !utils.IsValidJSON(data) {
@@ -142,28 +213,35 @@ func (p *Package) GetReleases() ([]Release, error) {
 
 	// filtering our drafts
 	releases = lo.Filter(releases, func(r Release, _ int) bool {
-		return !r.Draft && !r.Prerelease
+		return !r.Draft
 	})
 
 	p.Releases = releases
 	return releases, nil
 }
 
-func (asset *Asset) DownloadAsset(repo string) error {
+func (asset *Asset) DownloadAsset(ctx context.Context, repo string) error {
+	// the API downloads assets of private repositories too, and can resume them
+	token := utils.GitHubToken()
+	if asset.ID != 0 && token != "" {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/releases/assets/%d", repo, asset.ID)
+		return utils.DownloadFileWithHeaders(ctx, url, asset.Name, int64(asset.Size), map[string]string{
+			"Accept":        "application/octet-stream",
+			"Authorization": "token " + token,
+		})
+	}
+
 	started := time.Now().UnixMilli()
-	spin := spinner.New(constants.Clocks, 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	spin := spinner.New(constants.Clocks, 100*time.Millisecond, spinner.WithWriter(utils.ProgressOutput))
 	_ = spin.Color("bold", "fgHiYellow")
 	spin.Start()
 
 	// So that you get your cursor back
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
+	stop := utils.OnInterrupt(func() {
 		spin.Stop()
 		color.Yellow(" 😜 Operation aborted")
-		os.Exit(1)
-	}()
+	})
+	defer stop()
 
 	// unknown flag: --clobber even-though is on the docs https://cli.github.com/manual/gh_release_download 🤷
 	// Delete the file manually
@@ -173,14 +251,11 @@ func (asset *Asset) DownloadAsset(repo string) error {
 	}
 
 	// gh release download --repo bishopfox/bf --pattern fox_darwin_amd64_v1 v1.0.0 --dir . --clobber
-	// maybe can also use api call directly since ge saved the id
-	// curl -vLJO -H 'Authorization: token my_access_token' 'https://api.github.com/repos/:owner/:repo/releases/assets/:id'
-	// we get the built url from the asset itself
-	// "apiUrl": "https://api.github.com/repos/BishopFox/bf/releases/assets/<id>",
-	data, err := utils.ExecuteCommandAndGetOutput("gh", []string{"release", "download", "--repo", repo, "--pattern", asset.Name, asset.Tag, "--dir", "."}...)
+	data, err := utils.ExecuteCommandAndGetOutputContext(ctx, "gh", []string{"release", "download", "--repo", repo, "--pattern", asset.Name, asset.Tag, "--dir", "."}...)
 	if err != nil {
+		spin.Stop()
 		color.Red(data)
-		return utils.PrintAndReturnError(err.Error())
+		return utils.ClassifyGhError(data, utils.PrintAndReturnError(err.Error()))
 	}
 
 	spin.Stop()
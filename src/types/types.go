@@ -1,6 +1,8 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/samber/lo"
 
 	"github.com/ricardofabila/fox/src/constants"
@@ -9,8 +11,30 @@ import (
 // File to avoid circle imports
 
 type UserConfig struct {
-	AutoUpdate             bool `yaml:"autoUpdate"`
-	NotifyOutdatedVersions bool `yaml:"notifyOutdatedVersions"`
+	AutoUpdate             bool `json:"autoUpdate" yaml:"autoUpdate"`
+	NotifyOutdatedVersions bool `json:"notifyOutdatedVersions" yaml:"notifyOutdatedVersions"`
+	InstallManPages        bool `json:"installManPages" yaml:"installManPages"`
+	InstallCompletions     bool `json:"installCompletions" yaml:"installCompletions"`
+	// DownloadCacheSizeMB limits the downloads cache, 0 uses the default and a negative value disables it
+	DownloadCacheSizeMB int64 `json:"downloadCacheSizeMB" yaml:"downloadCacheSizeMB"`
+	// HTTPTimeoutSeconds is how long a request can wait for the server, 0 uses the default
+	HTTPTimeoutSeconds int64 `json:"httpTimeoutSeconds" yaml:"httpTimeoutSeconds"`
+	NetworkConfig      `yaml:",inline" mapstructure:",squash"`
+	// Hosts overrides the network settings for some hosts, eg: an internal artifacts server.
+	// gh gets the proxy of api.github.com and, on Linux, its caBundle, but never a client certificate.
+	Hosts map[string]NetworkConfig `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+// NetworkConfig is how fox connects to servers
+type NetworkConfig struct {
+	// Proxy is the URL of the proxy to use, HTTPS_PROXY and NO_PROXY are used when empty.
+	// In Hosts, "direct" connects without a proxy.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// CABundle is a PEM file with certificates to trust on top of the system ones
+	CABundle string `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+	// ClientCert and ClientKey are PEM files to authenticate to servers that ask for a certificate
+	ClientCert string `json:"clientCert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
 }
 
 type Installations struct {
@@ -24,6 +48,75 @@ type Installation struct {
 	Alias          string `yaml:"alias"`
 	RealName       string `yaml:"realName"`
 	Version        string `yaml:"version"`
+	// SHA256 and Size of the executable in constants.FoxBinPath at install time, used by 'fox verify'
+	SHA256 string `yaml:"sha256"`
+	Size   int64  `yaml:"size"`
+	// Executables are the extra executables installed along with the main one
+	Executables []InstalledExecutable `yaml:"executables,omitempty"`
+	// ShareFiles are the paths of the man pages and completions installed with the package
+	ShareFiles []string `yaml:"shareFiles,omitempty"`
+	// Env and Args are applied by the wrapper fox generates in constants.FoxBinPath
+	Env  map[string]string `yaml:"env,omitempty"`
+	Args []string          `yaml:"args,omitempty"`
+	// StorePath is where the real executable is when the installation runs through a wrapper
+	StorePath string `yaml:"storePath,omitempty"`
+	// StoreSHA256 and StoreSize are of the real executable at StorePath, SHA256 and Size are of the wrapper then
+	StoreSHA256 string `yaml:"storeSha256,omitempty"`
+	StoreSize   int64  `yaml:"storeSize,omitempty"`
+	// PreUninstall is the hook of the package to run before uninstalling, as it was at install time
+	PreUninstall string `yaml:"preUninstall,omitempty"`
+	// Pinned installations are skipped by 'fox upgrade', see 'fox pin'
+	Pinned bool `yaml:"pinned,omitempty"`
+}
+
+// DownloadCache is the index of the release assets kept in constants.FoxDownloadsCachePath
+type DownloadCache struct {
+	Downloads []CachedDownload `yaml:"downloads"`
+}
+
+type CachedDownload struct {
+	// AssetID is the id GitHub gives the asset, unique across every repository
+	AssetID int    `yaml:"assetId"`
+	Name    string `yaml:"name"`
+	Repo    string `yaml:"repo"`
+	Tag     string `yaml:"tag"`
+	SHA256  string `yaml:"sha256"`
+	Size    int64  `yaml:"size"`
+	// LastUsed is in unix milliseconds, the least recently used downloads are evicted first
+	LastUsed int64 `yaml:"lastUsed"`
+	// RunKey is the package, version and platform of an executable 'fox run' keeps, empty for release assets
+	RunKey string `yaml:"runKey,omitempty"`
+}
+
+// ApprovedHooks are the hook commands the user approved, by package
+type ApprovedHooks struct {
+	Packages map[string][]string `yaml:"packages"`
+}
+
+type InstalledExecutable struct {
+	Name   string `yaml:"name"`
+	SHA256 string `yaml:"sha256"`
+	Size   int64  `yaml:"size"`
+}
+
+// Files returns the names of every file the installation owns in constants.FoxBinPath
+func (i *Installation) Files() []string {
+	files := []string{i.RealName}
+	for _, e := range i.Executables {
+		files = append(files, e.Name)
+	}
+
+	return files
+}
+
+// IsWrapped tells if the executable in constants.FoxBinPath is a wrapper around StorePath
+func (i *Installation) IsWrapped() bool {
+	return i.StorePath != ""
+}
+
+// IsPinned tells if upgrades skip the installation. Installations named after their version, eg: tool@v1.0.0, always are
+func (i *Installation) IsPinned() bool {
+	return i.Pinned || strings.Contains(i.RealName, "@")
 }
 
 func (i *Installation) IsVisible() bool {
@@ -1,110 +1,67 @@
 package utils
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
-	"github.com/briandowns/spinner"
-	"github.com/fatih/color"
-
 	"github.com/ricardofabila/fox/src/constants"
 )
 
+// HTTPTimeout is how long a request can take to respond, and a download can go without receiving anything
+var HTTPTimeout = constants.DefaultHTTPTimeoutSeconds * time.Second
+
 func GetFromAPI(url string) ([]byte, error) {
 	httpClient := http.Client{
-		Timeout: time.Second * 2, // Timeout after 2 seconds
-	}
-
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	res, getErr := httpClient.Do(req)
-	if getErr != nil {
-		log.Fatal(getErr)
-	}
-
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		return nil, err
+		Timeout:   HTTPTimeout,
+		Transport: transport,
 	}
 
-	if res.Body != nil {
-		err = res.Body.Close()
+	var body []byte
+	err := withRetries(context.Background(), func() (bool, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
-	}
-
-	return body, nil
-}
 
-func DownloadFile(url, filename string) error {
-	started := time.Now().UnixMilli()
-	spin := spinner.New(constants.Clocks, 100*time.Millisecond, spinner.WithWriter(os.Stderr))
-	_ = spin.Color("bold", "fgHiYellow")
-	spin.Start()
-
-	// So that you get your cursor back
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		spin.Stop()
-		color.Yellow(" 😜 Operation aborted")
-		os.Exit(1)
-	}()
-
-	path := "./" + filename
-	// Delete the file manually
-	err := RemoveFile(path)
-	if err != nil {
-		return err
-	}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return isRetryable(err), err
+		}
+		defer res.Body.Close()
 
-	client := http.Client{
-		CheckRedirect: func(r *http.Request, via []*http.Request) error {
-			r.URL.Opaque = r.URL.Path
-			return nil
-		},
-	}
+		if res.StatusCode >= 500 {
+			return true, fmt.Errorf("the server responded %s for %s", res.Status, url)
+		}
 
-	// Put content on file
-	resp, err := client.Get(url)
-	if err != nil {
-		return err
-	}
+		if class := classifyStatus(res.StatusCode); class != nil {
+			return false, Classify(class, fmt.Errorf("the server responded %s for %s", res.Status, url))
+		}
 
-	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		return err
-	}
+		body, err = io.ReadAll(res.Body)
+		return isRetryable(err), err
+	})
 
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return err
-	}
+	return body, classifyNetworkError(err)
+}
 
-	err = resp.Body.Close()
-	if err != nil {
-		return err
+// GitHubToken returns the token to call the GitHub API with, from the environment or the gh CLI.
+// It is empty when there is none.
+func GitHubToken() string {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token
+		}
 	}
 
-	err = file.Close()
+	token, err := ExecuteCommandAndGetOutput("gh", "auth", "token")
 	if err != nil {
-		return err
+		return ""
 	}
 
-	spin.Stop()
-	ended := time.Now().UnixMilli()
-	timeItTook := float64(ended-started) / 1000
-	color.Green(" Downloaded in %.2f seconds!", timeItTook)
-
-	return nil
+	return strings.TrimSpace(token)
 }
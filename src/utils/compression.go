@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+type Compression string
+
+const (
+	NoCompression Compression = ""
+	Gzip          Compression = "gzip"
+	Bzip2         Compression = "bzip2"
+	Xz            Compression = "xz"
+	Zstd          Compression = "zstd"
+)
+
+type ArchiveType string
+
+const (
+	// NotAnArchive is a file as is, most likely the executable itself
+	NotAnArchive ArchiveType = ""
+	TarArchive   ArchiveType = "tar"
+	ZIPArchive   ArchiveType = "zip"
+	// CompressedFile is a single compressed file, eg: tool.gz
+	CompressedFile ArchiveType = "compressed"
+	DebPackage     ArchiveType = "deb"
+	RPMPackage     ArchiveType = "rpm"
+)
+
+var magicNumbers = map[Compression][]byte{
+	Gzip:  {0x1f, 0x8b},
+	Bzip2: []byte("BZh"),
+	Xz:    {0xfd, '7', 'z', 'X', 'Z', 0x00},
+	Zstd:  {0x28, 0xb5, 0x2f, 0xfd},
+}
+
+var zipMagicNumber = []byte("PK\x03\x04")
+
+// tar headers have "ustar" at this offset
+const tarMagicOffset = 257
+
+func FileHasCompressedExtension(filename string) bool {
+	for _, extension := range constants.CompressedExtensions {
+		if strings.HasSuffix(filename, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DetectCompression tells the compression of a stream by its first bytes
+func DetectCompression(header []byte) Compression {
+	for compression, magic := range magicNumbers {
+		if bytes.HasPrefix(header, magic) {
+			return compression
+		}
+	}
+
+	return NoCompression
+}
+
+// Decompress wraps the reader with the right decompressor, if it is compressed at all.
+// The returned closer must be called once done, it does not close the original reader.
+func Decompress(r io.Reader) (*bufio.Reader, func(), error) {
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(6)
+
+	var decompressed io.Reader
+	closer := func() {}
+	switch DetectCompression(header) {
+	case Gzip:
+		gzr, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		decompressed = gzr
+		closer = func() { _ = gzr.Close() }
+	case Bzip2:
+		decompressed = bzip2.NewReader(buffered)
+	case Xz:
+		xzr, err := xz.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		decompressed = xzr
+	case Zstd:
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		decompressed = zr
+		closer = zr.Close
+	default:
+		return buffered, closer, nil
+	}
+
+	return bufio.NewReader(decompressed), closer, nil
+}
+
+func isTar(r *bufio.Reader) bool {
+	header, _ := r.Peek(tarMagicOffset + 5)
+	return len(header) == tarMagicOffset+5 && string(header[tarMagicOffset:]) == "ustar"
+}
+
+// DetectArchive tells what kind of archive a file is by its contents, falling back to its extension
+func DetectArchive(path string) (ArchiveType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return NotAnArchive, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(len(debMagicNumber))
+	if bytes.HasPrefix(header, zipMagicNumber) {
+		return ZIPArchive, nil
+	}
+
+	if bytes.HasPrefix(header, debMagicNumber) {
+		return DebPackage, nil
+	}
+
+	if bytes.HasPrefix(header, rpmMagicNumber) {
+		return RPMPackage, nil
+	}
+
+	// Alpine packages are gzipped tars, so they are handled as such
+
+	compression := DetectCompression(header)
+	decompressed, closeDecompressor, err := Decompress(buffered)
+	if err != nil {
+		return NotAnArchive, fmt.Errorf("error reading the %s file %s: %s", compression, path, err)
+	}
+	defer closeDecompressor()
+
+	if isTar(decompressed) || FileHasTarExtension(path) {
+		return TarArchive, nil
+	}
+
+	if compression != NoCompression {
+		return CompressedFile, nil
+	}
+
+	return NotAnArchive, nil
+}
+
+// DecompressFile decompresses a single compressed file, eg: tool.gz, into dst.
+// It stops at MaxExtractedSize like the archives do, leaving no dst behind.
+func DecompressFile(src, dst string) error {
+	return decompressFile(src, dst, &extractionBudget{})
+}
+
+func decompressFile(src, dst string, budget *extractionBudget) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decompressed, closeDecompressor, err := Decompress(file)
+	if err != nil {
+		return err
+	}
+	defer closeDecompressor()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+
+	if err = budget.copy(out, decompressed); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
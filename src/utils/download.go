@@ -0,0 +1,419 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/samber/lo"
+)
+
+// DownloadRetries is how many times a request is tried before giving up
+const DownloadRetries = 5
+
+// ProgressOutput is where the progress of downloads is shown
+var ProgressOutput io.Writer = os.Stderr
+
+// errRestartDownload means the partial download can't be resumed and has to start over
+var errRestartDownload = errors.New("the partial download can't be resumed")
+
+// withRetries calls attempt until it succeeds, it says the error is not retryable, the retries run out
+// or the context is done, waiting twice as long after each failure
+func withRetries(ctx context.Context, attempt func() (bool, error)) error {
+	wait := time.Second
+	var err error
+	for i := 1; i <= DownloadRetries; i++ {
+		var retryable bool
+		retryable, err = attempt()
+		if err == nil || !retryable || i == DownloadRetries || ctx.Err() != nil {
+			return err
+		}
+
+		color.Yellow(" %s, retrying in %s (%d/%d)", err, wait, i, DownloadRetries-1)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wait *= 2
+	}
+
+	return err
+}
+
+// isRetryable tells if the error is likely to go away by trying again, eg: a connection reset
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// every *url.Error is a net.Error, even for an untrusted certificate that won't change by trying again
+	var opErr *net.OpError
+	var netErr net.Error
+	if errors.As(err, &opErr) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, errRestartDownload) ||
+		strings.Contains(err.Error(), "connection reset")
+}
+
+func DownloadFile(url, filename string, size int64) error {
+	return DownloadFileWithHeaders(context.Background(), url, filename, size, nil)
+}
+
+// DownloadFileWithHeaders downloads url into ./filename. The bytes are written to ./filename.part first,
+// so an interrupted, or cancelled, download resumes where it was left with an HTTP Range request.
+// Only a partial download of the same url is resumed, and only if the server still has the same file.
+// size is what the file must weigh, 0 when unknown.
+func DownloadFileWithHeaders(ctx context.Context, url, filename string, size int64, headers map[string]string) error {
+	started := time.Now()
+	path := "./" + filename
+	partPath := path + ".part"
+
+	// the partial download may be of the same asset name from another release, or another repository
+	if loadPartialDownload(partPath).URL != url {
+		removePartialDownload(partPath)
+	}
+
+	// So that you get your cursor back, the partial download is kept to resume it
+	stop := OnInterrupt(func() {
+		fmt.Fprintln(ProgressOutput)
+		color.Yellow(" 😜 Operation aborted, run it again to resume the download")
+	})
+	defer stop()
+
+	client := http.Client{
+		Transport: transport,
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			r.URL.Opaque = r.URL.Path
+			return nil
+		},
+	}
+
+	err := withRetries(ctx, func() (bool, error) {
+		err := downloadPart(ctx, &client, url, partPath, size, headers)
+		return isRetryable(err), err
+	})
+	if err != nil {
+		return classifyNetworkError(err)
+	}
+
+	if info, err := os.Stat(partPath); err == nil && size > 0 && info.Size() != size {
+		removePartialDownload(partPath)
+		return fmt.Errorf("error downloading %s: expected %d bytes, got %d", url, size, info.Size())
+	}
+
+	err = os.Rename(partPath, path)
+	if err != nil {
+		return err
+	}
+	removePartialDownload(partPath)
+
+	color.Green(" Downloaded in %.2f seconds!", time.Since(started).Seconds())
+
+	return nil
+}
+
+// partialDownload is kept next to a .part file, what it was downloaded from and how to tell if the file changed since
+type partialDownload struct {
+	URL string
+	// Validator is the ETag, or the Last-Modified date, of the file, sent back in If-Range
+	Validator string
+}
+
+func partialDownloadPath(partPath string) string {
+	return partPath + ".info"
+}
+
+func loadPartialDownload(partPath string) partialDownload {
+	data, err := os.ReadFile(partialDownloadPath(partPath))
+	if err != nil {
+		return partialDownload{}
+	}
+
+	url, validator, _ := strings.Cut(string(data), "\n")
+	return partialDownload{URL: url, Validator: strings.TrimSpace(validator)}
+}
+
+func savePartialDownload(partPath string, partial partialDownload) error {
+	return os.WriteFile(partialDownloadPath(partPath), []byte(partial.URL+"\n"+partial.Validator+"\n"), 0666)
+}
+
+func removePartialDownload(partPath string) {
+	_ = os.Remove(partPath)
+	_ = os.Remove(partialDownloadPath(partPath))
+}
+
+// validator is what tells if the file changed between requests, If-Range only takes strong ETags
+func validator(resp *http.Response) string {
+	etag := resp.Header.Get("ETag")
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// downloadPart downloads what is missing from partPath
+func downloadPart(parent context.Context, client *http.Client, url, partPath string, size int64, headers map[string]string) error {
+	partial := loadPartialDownload(partPath)
+	offset := int64(0)
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	// without a validator there is no telling if the rest of the file is of the same file
+	if offset > 0 && partial.Validator == "" {
+		removePartialDownload(partPath)
+		offset = 0
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		// the server sends the whole file instead when it changed
+		req.Header.Set("If-Range", partial.Validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	rangeTotal := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		var start int64
+		start, rangeTotal = contentRange(resp.Header.Get("Content-Range"))
+		if start != offset || (size > 0 && rangeTotal != size) {
+			removePartialDownload(partPath)
+			return errRestartDownload
+		}
+
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// the partial download is complete, or bigger than the file now is
+		if _, total := contentRange(resp.Header.Get("Content-Range")); total == offset && (size <= 0 || total == size) {
+			return nil
+		}
+
+		removePartialDownload(partPath)
+		return errRestartDownload
+	case resp.StatusCode >= 500:
+		return &net.OpError{Op: "download", Err: fmt.Errorf("the server responded %s", resp.Status)}
+	case resp.StatusCode != http.StatusOK:
+		return Classify(classifyStatus(resp.StatusCode), fmt.Errorf("error downloading %s: the server responded %s", url, resp.Status))
+	default:
+		// the server ignored the range, or the file changed, start over
+		flags |= os.O_TRUNC
+		offset = 0
+		if err = savePartialDownload(partPath, partialDownload{URL: url, Validator: validator(resp)}); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0666)
+	if err != nil {
+		return err
+	}
+
+	progress := newProgress(offset, progressTotal(offset, resp.ContentLength, rangeTotal))
+	defer progress.done()
+
+	// cancel the request when nothing arrives for too long, a stalled connection never fails on its own
+	stalled := time.AfterFunc(HTTPTimeout, cancel)
+	defer stalled.Stop()
+
+	buffer := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buffer)
+		if n > 0 {
+			stalled.Reset(HTTPTimeout)
+			if _, err = file.Write(buffer[:n]); err != nil {
+				_ = file.Close()
+				return err
+			}
+			progress.add(int64(n))
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+
+		if readErr != nil {
+			_ = file.Close()
+			if parent.Err() != nil {
+				return parent.Err()
+			}
+
+			if ctx.Err() != nil {
+				return &net.OpError{Op: "download", Err: fmt.Errorf("no data received for %s", HTTPTimeout)}
+			}
+
+			return readErr
+		}
+	}
+
+	return file.Close()
+}
+
+// progressTotal is the size of the whole file, or -1 when neither the range nor the length of the response tell it
+func progressTotal(offset, contentLength, rangeTotal int64) int64 {
+	if rangeTotal > 0 {
+		return rangeTotal
+	}
+
+	if contentLength < 0 {
+		return -1
+	}
+
+	return offset + contentLength
+}
+
+// classifyNetworkError marks errors that are not classified yet as network errors
+func classifyNetworkError(err error) error {
+	for _, class := range []error{ErrAuth, ErrNotFound, ErrNetwork} {
+		if errors.Is(err, class) {
+			return err
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || isRetryable(err) {
+		return Classify(ErrNetwork, err)
+	}
+
+	return err
+}
+
+// contentRange returns the first byte and the total of a Content-Range header, eg: bytes 100-1233/1234 or bytes */1234.
+// They are -1 when missing or invalid.
+func contentRange(header string) (start, total int64) {
+	start, total = -1, -1
+	byteRange, totalText, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(header), "bytes "), "/")
+	if !found {
+		return start, total
+	}
+
+	if first, _, found := strings.Cut(byteRange, "-"); found {
+		if parsed, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64); err == nil {
+			start = parsed
+		}
+	}
+
+	if parsed, err := strconv.ParseInt(strings.TrimSpace(totalText), 10, 64); err == nil {
+		total = parsed
+	}
+
+	return start, total
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd()))
+}
+
+// progress reports how a download goes, as a bar on a terminal and as log lines otherwise
+type progress struct {
+	started    time.Time
+	resumedAt  int64
+	downloaded int64
+	total      int64
+	isTerminal bool
+	lastReport time.Time
+	lastLogged int64
+}
+
+func newProgress(resumedAt, total int64) *progress {
+	if resumedAt > 0 {
+		color.Magenta(" Resuming the download at %s", ByteCountIEC(resumedAt))
+	}
+
+	return &progress{
+		started:    time.Now(),
+		resumedAt:  resumedAt,
+		downloaded: resumedAt,
+		total:      total,
+		isTerminal: isTerminal(ProgressOutput),
+	}
+}
+
+func (p *progress) add(n int64) {
+	p.downloaded += n
+	if time.Since(p.lastReport) < 200*time.Millisecond {
+		return
+	}
+	p.lastReport = time.Now()
+
+	if p.isTerminal {
+		fmt.Fprint(ProgressOutput, "\r"+p.line()+"   ")
+		return
+	}
+
+	// every 10%, or every 10MiB when the size is unknown
+	step := lo.Ternary(p.total > 0, p.total/10, 10*1024*1024)
+	if p.downloaded-p.lastLogged >= step {
+		p.lastLogged = p.downloaded
+		fmt.Fprintln(ProgressOutput, " "+p.line())
+	}
+}
+
+func (p *progress) done() {
+	if p.isTerminal && !p.lastReport.IsZero() {
+		fmt.Fprint(ProgressOutput, "\r"+p.line()+"   \n")
+	}
+}
+
+// line is eg: [=========>          ] 45% 45.0 MiB/100.0 MiB 5.1 MiB/s ETA 11s
+func (p *progress) line() string {
+	elapsed := time.Since(p.started).Seconds()
+	speed := float64(p.downloaded-p.resumedAt) / lo.Ternary(elapsed > 0, elapsed, 1)
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %s/s", ByteCountIEC(p.downloaded), ByteCountIEC(int64(speed)))
+	}
+
+	const width = 30
+	done := int(float64(width) * float64(p.downloaded) / float64(p.total))
+	if done > width {
+		done = width
+	}
+	bar := strings.Repeat("=", done) + strings.Repeat(" ", width-done)
+	if done < width {
+		bar = bar[:done] + ">" + bar[done+1:]
+	}
+
+	eta := "?"
+	if speed > 0 {
+		eta = (time.Duration(float64(p.total-p.downloaded)/speed) * time.Second).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("[%s] %3d%% %s/%s %s/s ETA %s", bar, p.downloaded*100/p.total, ByteCountIEC(p.downloaded), ByteCountIEC(p.total), ByteCountIEC(int64(speed)), eta)
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inTempDir runs the test from an empty directory, downloads go to the working directory
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+// fileServer serves content with an ETag, handling Range and If-Range like GitHub does
+func fileServer(t *testing.T, content []byte, etag string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := []byte(strings.Repeat("new release ", 1000))
+
+	tests := []struct {
+		name string
+		// part is what a previous download left in asset.part, with the url and validator it was downloaded with
+		part      []byte
+		partURL   string
+		validator string
+		size      int64
+		wantErr   string
+	}{
+		{name: "no partial download", size: int64(len(content))},
+		{name: "resumes the same file", part: content[:100], partURL: "same", validator: `"v2"`, size: int64(len(content))},
+		{name: "partial download of another url", part: []byte("old release from another url"), partURL: "http://example.com/other", validator: `"v2"`, size: int64(len(content))},
+		{name: "the file changed on the server", part: []byte("old release"), partURL: "same", validator: `"v1"`, size: int64(len(content))},
+		{name: "partial download without a validator", part: []byte("old release"), partURL: "same", size: int64(len(content))},
+		{name: "partial download from before fox kept the url", part: []byte("old release"), size: int64(len(content))},
+		{name: "complete partial download", part: content, partURL: "same", validator: `"v2"`, size: int64(len(content))},
+		{name: "unknown size", part: content[:100], partURL: "same", validator: `"v2"`},
+		{name: "the size doesn't match", part: content[:100], partURL: "same", validator: `"v2"`, size: 10, wantErr: "expected 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inTempDir(t)
+			server := fileServer(t, content, `"v2"`)
+			url := server.URL + "/asset"
+
+			if tt.part != nil {
+				if err := os.WriteFile("asset.part", tt.part, 0666); err != nil {
+					t.Fatal(err)
+				}
+
+				partURL := tt.partURL
+				if partURL == "same" {
+					partURL = url
+				}
+
+				if partURL != "" {
+					if err := savePartialDownload("./asset.part", partialDownload{URL: partURL, Validator: tt.validator}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			err := DownloadFileWithHeaders(context.Background(), url, "asset", tt.size, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			downloaded, err := os.ReadFile("asset")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(downloaded, content) {
+				t.Errorf("the downloaded file is corrupted, got %d bytes starting with %q", len(downloaded), downloaded[:20])
+			}
+
+			for _, leftover := range []string{"asset.part", "asset.part.info"} {
+				if _, err = os.Stat(leftover); err == nil {
+					t.Errorf("%s was not removed", leftover)
+				}
+			}
+		})
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		header       string
+		start, total int64
+	}{
+		{header: "bytes 100-199/200", start: 100, total: 200},
+		{header: "bytes */1234", start: -1, total: 1234},
+		{header: "bytes 0-9/*", start: 0, total: -1},
+		{header: "", start: -1, total: -1},
+	}
+
+	for _, tt := range tests {
+		start, total := contentRange(tt.header)
+		if start != tt.start || total != tt.total {
+			t.Errorf("%q: expected %d and %d, got %d and %d", tt.header, tt.start, tt.total, start, total)
+		}
+	}
+}
+
+func TestProgressTotal(t *testing.T) {
+	tests := []struct {
+		offset, contentLength, rangeTotal int64
+		expected                          int64
+	}{
+		{offset: 0, contentLength: 200, rangeTotal: -1, expected: 200},
+		{offset: 0, contentLength: -1, rangeTotal: -1, expected: -1},
+		{offset: 100, contentLength: 100, rangeTotal: 200, expected: 200},
+		{offset: 100, contentLength: -1, rangeTotal: 200, expected: 200},
+		{offset: 100, contentLength: 100, rangeTotal: -1, expected: 200},
+		{offset: 100, contentLength: -1, rangeTotal: -1, expected: -1},
+	}
+
+	for _, tt := range tests {
+		if total := progressTotal(tt.offset, tt.contentLength, tt.rangeTotal); total != tt.expected {
+			t.Errorf("offset %d, length %d and range total %d: expected %d, got %d", tt.offset, tt.contentLength, tt.rangeTotal, tt.expected, total)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// The classes of errors fox returns, check them with errors.Is. The commands exit with a different code for each.
+var (
+	// ErrNetwork is a server that can't be reached, times out or fails
+	ErrNetwork = errors.New("network error")
+	// ErrAuth is a missing or invalid token, or a resource the user has no access to
+	ErrAuth = errors.New("authentication error")
+	// ErrNotFound is a package, version, release or asset that doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrStateCorruption is a file fox keeps, eg: installations.yaml, that can't be read
+	ErrStateCorruption = errors.New("corrupted state")
+	// ErrAborted is the user saying no, or pressing Control+C, at a prompt
+	ErrAborted = errors.New("aborted")
+)
+
+// classifiedError is an error that is also one of the classes above, without changing its message
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+// Classify marks the error as one of the classes, eg: utils.Classify(ErrNotFound, err)
+func Classify(class, err error) error {
+	if err == nil || errors.Is(err, class) {
+		return err
+	}
+
+	return &classifiedError{class: class, err: err}
+}
+
+// classifyStatus returns the class of an HTTP status code, nil when it is not an error
+func classifyStatus(status int) error {
+	switch {
+	case status == 401 || status == 403:
+		return ErrAuth
+	case status == 404 || status == 410:
+		return ErrNotFound
+	case status >= 400:
+		return ErrNetwork
+	}
+
+	return nil
+}
+
+// ClassifyGhError classifies the error of a gh command from what it printed
+func ClassifyGhError(output string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "http 401"), strings.Contains(lower, "http 403"),
+		strings.Contains(lower, "bad credentials"), strings.Contains(lower, "gh auth login"):
+		return Classify(ErrAuth, err)
+	case strings.Contains(lower, "http 404"), strings.Contains(lower, "not found"),
+		strings.Contains(lower, "could not resolve to a repository"):
+		return Classify(ErrNotFound, err)
+	case strings.Contains(lower, "error connecting"), strings.Contains(lower, "dial tcp"),
+		strings.Contains(lower, "timeout"), strings.Contains(lower, "http 5"):
+		return Classify(ErrNetwork, err)
+	}
+
+	return err
+}
+
+// OnInterrupt calls cleanup when the user presses Control+C, then lets the signal do what it would have done:
+// end the process, or reach the program embedding fox if it handles it. Call the returned func when done.
+func OnInterrupt(cleanup func()) func() {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-c:
+			cleanup()
+			signal.Stop(c)
+			if process, err := os.FindProcess(os.Getpid()); err == nil {
+				_ = process.Signal(sig)
+			}
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
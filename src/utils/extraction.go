@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Limits that protect against archive bombs, no release of a CLI tool gets anywhere near them
+const (
+	MaxExtractedSize  int64 = 2 << 30 // 2 GiB
+	MaxExtractedFiles       = 20000
+)
+
+// extractionBudget keeps track of how much an archive has extracted so far
+type extractionBudget struct {
+	size  int64
+	files int
+	// maxSize and maxFiles are MaxExtractedSize and MaxExtractedFiles when zero
+	maxSize  int64
+	maxFiles int
+}
+
+func (b *extractionBudget) limits() (int64, int) {
+	maxSize, maxFiles := b.maxSize, b.maxFiles
+	if maxSize == 0 {
+		maxSize = MaxExtractedSize
+	}
+	if maxFiles == 0 {
+		maxFiles = MaxExtractedFiles
+	}
+
+	return maxSize, maxFiles
+}
+
+func (b *extractionBudget) addFile() error {
+	_, maxFiles := b.limits()
+	b.files++
+	if b.files > maxFiles {
+		return fmt.Errorf("the archive has more than %d files, refusing to extract it", maxFiles)
+	}
+
+	return nil
+}
+
+// copy copies at most what is left of the budget, failing if the file is bigger than that
+func (b *extractionBudget) copy(dst io.Writer, src io.Reader) error {
+	maxSize, _ := b.limits()
+	left := maxSize - b.size
+	written, err := io.Copy(dst, io.LimitReader(src, left+1))
+	b.size += written
+	if err != nil {
+		return err
+	}
+
+	if b.size > maxSize {
+		return fmt.Errorf("the archive extracts to more than %d bytes, refusing to extract it", maxSize)
+	}
+
+	return nil
+}
+
+// safeJoin joins the name of an archive entry onto dst, making sure the result stays inside dst
+func safeJoin(dst, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("invalid file path in archive, it is absolute: " + name)
+	}
+
+	path := filepath.Join(dst, name)
+	if !isInside(dst, path) {
+		return "", fmt.Errorf("invalid file path in archive, it escapes the destination: " + name)
+	}
+
+	return path, nil
+}
+
+func isInside(dst, path string) bool {
+	root := filepath.Clean(dst)
+	path = filepath.Clean(path)
+	return path == root || strings.HasPrefix(path, root+string(os.PathSeparator))
+}
+
+// safeDirectory creates the directory at path, only if it really is inside dst once the links extracted so far
+// are followed. eg: with link -> .., link/evil is rejected
+func safeDirectory(dst, path string) error {
+	resolvedDst, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	// MkdirAll follows the links on the way, the deepest directory that exists must be inside
+	existing := path
+	for {
+		if _, err = os.Lstat(existing); err == nil || existing == filepath.Dir(existing) {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil || !isInside(resolvedDst, resolved) {
+		return fmt.Errorf("invalid file path in archive, it escapes the destination through a link: " + path)
+	}
+
+	if err = os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	resolved, err = filepath.EvalSymlinks(path)
+	if err != nil || !isInside(resolvedDst, resolved) {
+		return fmt.Errorf("invalid file path in archive, it escapes the destination through a link: " + path)
+	}
+
+	return nil
+}
+
+// resolveLinkTarget follows target from the directory the link is in, one part at a time, through the links
+// extracted so far. ok is false when any step leaves root, where root and directory have no links themselves.
+func resolveLinkTarget(root, directory, target string) (resolved string, ok bool) {
+	resolved = directory
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+		default:
+			resolved = filepath.Join(resolved, part)
+			if info, err := os.Lstat(resolved); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				// a dangling link can't be checked, it could be pointed anywhere later
+				if resolved, err = filepath.EvalSymlinks(resolved); err != nil {
+					return "", false
+				}
+			}
+		}
+
+		if !isInside(root, resolved) {
+			return "", false
+		}
+	}
+
+	return resolved, true
+}
+
+// safeSymlink creates a symlink at path, only if its target resolves inside dst, following the links on the way.
+// eg: bin/tool -> ../libexec/tool
+func safeSymlink(dst, path, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("invalid symlink in archive, it points to an absolute path: " + path + " -> " + target)
+	}
+
+	if !isInside(dst, filepath.Join(filepath.Dir(path), target)) {
+		return fmt.Errorf("invalid symlink in archive, it points outside the archive: " + path + " -> " + target)
+	}
+
+	if err := safeDirectory(dst, filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	// the directory of the link could itself be a link pointing somewhere else
+	resolvedDirectory, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	resolvedDst, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := resolveLinkTarget(resolvedDst, resolvedDirectory, target); !ok {
+		return fmt.Errorf("invalid symlink in archive, it points outside the archive: " + path + " -> " + target)
+	}
+
+	_ = os.Remove(path)
+	return os.Symlink(target, path)
+}
+
+// sanitizeMode keeps only the permission bits of an archive entry, never setuid, setgid or sticky,
+// and makes sure the owner can read and write what fox extracted
+func sanitizeMode(mode os.FileMode) os.FileMode {
+	return mode.Perm()&0755 | 0600
+}
+
+// createExtractedFile creates the file at path, whose real directory must be inside dst, and writes the contents of r into it
+func createExtractedFile(dst, path string, mode os.FileMode, r io.Reader, budget *extractionBudget) error {
+	if err := safeDirectory(dst, filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	// never write through a symlink planted by an earlier entry
+	_ = os.Remove(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, sanitizeMode(mode))
+	if err != nil {
+		return err
+	}
+
+	if err = budget.copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,428 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveEntry is a file, directory or link of an archive built for a test
+type archiveEntry struct {
+	name     string
+	body     string
+	symlink  string
+	hardlink string
+	dir      bool
+}
+
+func tarArchive(t *testing.T, entries []archiveEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case e.dir:
+			header.Typeflag, header.Size = tar.TypeDir, 0
+		case e.symlink != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, e.symlink, 0
+		case e.hardlink != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeLink, e.hardlink, 0
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func zipArchive(t *testing.T, entries []archiveEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		switch {
+		case e.dir:
+			header.Name = strings.TrimSuffix(e.name, "/") + "/"
+			header.SetMode(os.ModeDir | 0755)
+		case e.symlink != "":
+			header.SetMode(os.ModeSymlink | 0777)
+			body = e.symlink
+		default:
+			header.SetMode(0755)
+		}
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// destination is an empty directory to extract to, inside another one to catch what escapes it
+func destination(t *testing.T) (root, dst string) {
+	t.Helper()
+	root = t.TempDir()
+	dst = filepath.Join(root, "dst")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return root, dst
+}
+
+// assertNothingEscaped fails if anything but the destination was created next to it
+func assertNothingEscaped(t *testing.T, root string) {
+	t.Helper()
+	files, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if f.Name() != "dst" {
+			t.Errorf("the archive wrote outside the destination: %s", f.Name())
+		}
+	}
+}
+
+func assertExtractionError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" && err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+		t.Fatalf("expected an error containing %q, got: %v", wantErr, err)
+	}
+}
+
+func TestExtractTAR(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		wantErr string
+		// files are the extracted files and their contents, when there is no error
+		files map[string]string
+	}{
+		{
+			name:    "parent directory",
+			entries: []archiveEntry{{name: "../evil", body: "x"}},
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "parent directory in the middle",
+			entries: []archiveEntry{{name: "bin/../../evil", body: "x"}},
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "absolute path",
+			entries: []archiveEntry{{name: "/tmp/evil", body: "x"}},
+			wantErr: "it is absolute",
+		},
+		{
+			name:    "symlink outside",
+			entries: []archiveEntry{{name: "link", symlink: "../evil"}},
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "absolute symlink",
+			entries: []archiveEntry{{name: "link", symlink: "/etc/passwd"}},
+			wantErr: "points to an absolute path",
+		},
+		{
+			name: "symlink through a symlinked directory",
+			entries: []archiveEntry{
+				{name: "bin", symlink: "."},
+				{name: "bin/link", symlink: "../evil"},
+			},
+			wantErr: "points outside the archive",
+		},
+		{
+			name: "symlink through a chain of symlinks",
+			entries: []archiveEntry{
+				{name: "l", symlink: "."},
+				{name: "m", symlink: "l/.."},
+				{name: "m/evil", body: "x"},
+			},
+			wantErr: "points outside the archive",
+		},
+		{
+			name: "file written through a symlink that was pointed outside later",
+			entries: []archiveEntry{
+				{name: "x/", dir: true},
+				{name: "l", symlink: "x"},
+				{name: "m", symlink: "l/.."},
+				{name: "l", symlink: "."},
+				{name: "m/evil", body: "x"},
+			},
+			wantErr: "escapes the destination through a link",
+		},
+		{
+			name: "directory created through a symlink outside",
+			entries: []archiveEntry{
+				{name: "x/", dir: true},
+				{name: "l", symlink: "x"},
+				{name: "m", symlink: "l/.."},
+				{name: "l", symlink: "."},
+				{name: "m/sub/", dir: true},
+			},
+			wantErr: "escapes the destination through a link",
+		},
+		{
+			name: "file written through a symlink",
+			entries: []archiveEntry{
+				{name: "tool", body: "inside"},
+				{name: "link", symlink: "tool"},
+				{name: "link", body: "replaced"},
+			},
+			files: map[string]string{"tool": "inside", "link": "replaced"},
+		},
+		{
+			name: "hardlink",
+			entries: []archiveEntry{
+				{name: "bin/tool", body: "#!/bin/sh"},
+				{name: "bin/alias", hardlink: "bin/tool"},
+			},
+			files: map[string]string{"bin/tool": "#!/bin/sh", "bin/alias": "#!/bin/sh"},
+		},
+		{
+			name:    "hardlink outside",
+			entries: []archiveEntry{{name: "passwd", hardlink: "../../etc/passwd"}},
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "absolute hardlink",
+			entries: []archiveEntry{{name: "passwd", hardlink: "/etc/passwd"}},
+			wantErr: "it is absolute",
+		},
+		{
+			name:    "hardlink to a file not in the archive",
+			entries: []archiveEntry{{name: "tool", hardlink: "missing"}},
+			wantErr: "its target was not extracted",
+		},
+		{
+			name: "hardlink through a symlink outside",
+			entries: []archiveEntry{
+				{name: "up", symlink: "."},
+				{name: "passwd", hardlink: "up/../../etc/passwd"},
+			},
+			wantErr: "escapes the destination",
+		},
+		{
+			name: "valid archive",
+			entries: []archiveEntry{
+				{name: "tool-1.0/", dir: true},
+				{name: "tool-1.0/libexec/tool", body: "binary"},
+				{name: "tool-1.0/bin/tool", symlink: "../libexec/tool"},
+			},
+			files: map[string]string{"tool-1.0/libexec/tool": "binary", "tool-1.0/bin/tool": "binary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, dst := destination(t)
+			err := extractTARStream(tarArchive(t, tt.entries), dst, &extractionBudget{})
+			assertExtractionError(t, err, tt.wantErr)
+			assertNothingEscaped(t, root)
+
+			for name, content := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dst, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != content {
+					t.Errorf("%s: expected %q, got %q", name, content, data)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractZIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		wantErr string
+		files   map[string]string
+	}{
+		{
+			name:    "parent directory",
+			entries: []archiveEntry{{name: "../evil", body: "x"}},
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "absolute path",
+			entries: []archiveEntry{{name: "/tmp/evil", body: "x"}},
+			wantErr: "it is absolute",
+		},
+		{
+			name:    "symlink outside",
+			entries: []archiveEntry{{name: "link", symlink: "../evil"}},
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "absolute symlink",
+			entries: []archiveEntry{{name: "link", symlink: "/etc/passwd"}},
+			wantErr: "points to an absolute path",
+		},
+		{
+			name: "symlink through a symlinked directory",
+			entries: []archiveEntry{
+				{name: "bin", symlink: "."},
+				{name: "bin/link", symlink: "../evil"},
+			},
+			wantErr: "points outside the archive",
+		},
+		{
+			name: "valid archive",
+			entries: []archiveEntry{
+				{name: "tool/", dir: true},
+				{name: "tool/libexec/tool", body: "binary"},
+				{name: "tool/bin/tool", symlink: "../libexec/tool"},
+			},
+			files: map[string]string{"tool/libexec/tool": "binary", "tool/bin/tool": "binary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, dst := destination(t)
+			err := ExtractZIP(zipArchive(t, tt.entries), dst)
+			assertExtractionError(t, err, tt.wantErr)
+			assertNothingEscaped(t, root)
+
+			for name, content := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dst, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != content {
+					t.Errorf("%s: expected %q, got %q", name, content, data)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractionLimits(t *testing.T) {
+	files := func(n, size int) []archiveEntry {
+		var entries []archiveEntry
+		for i := 0; i < n; i++ {
+			entries = append(entries, archiveEntry{name: "file" + strings.Repeat("x", i), body: strings.Repeat("a", size)})
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		budget  extractionBudget
+		wantErr string
+	}{
+		{name: "at the file limit", entries: files(3, 1), budget: extractionBudget{maxFiles: 3}},
+		{name: "over the file limit", entries: files(4, 1), budget: extractionBudget{maxFiles: 3}, wantErr: "more than 3 files"},
+		{name: "at the byte limit", entries: files(2, 5), budget: extractionBudget{maxSize: 10}},
+		{name: "over the byte limit", entries: files(2, 6), budget: extractionBudget{maxSize: 10}, wantErr: "more than 10 bytes"},
+		{name: "a single file over the byte limit", entries: files(1, 11), budget: extractionBudget{maxSize: 10}, wantErr: "more than 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run("tar "+tt.name, func(t *testing.T) {
+			_, dst := destination(t)
+			budget := tt.budget
+			assertExtractionError(t, extractTARStream(tarArchive(t, tt.entries), dst, &budget), tt.wantErr)
+		})
+
+		t.Run("zip "+tt.name, func(t *testing.T) {
+			_, dst := destination(t)
+			budget := tt.budget
+			assertExtractionError(t, extractZIP(zipArchive(t, tt.entries), dst, &budget), tt.wantErr)
+		})
+	}
+}
+
+func TestDecompressFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		budget  extractionBudget
+		wantErr string
+	}{
+		{name: "at the byte limit", content: strings.Repeat("a", 10), budget: extractionBudget{maxSize: 10}},
+		{name: "over the byte limit", content: strings.Repeat("a", 11), budget: extractionBudget{maxSize: 10}, wantErr: "more than 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			if _, err := gw.Write([]byte(tt.content)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := gw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+			src, dst := filepath.Join(dir, "tool.gz"), filepath.Join(dir, "tool")
+			if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			budget := tt.budget
+			assertExtractionError(t, decompressFile(src, dst, &budget), tt.wantErr)
+			if _, err := os.Stat(dst); (err == nil) != (tt.wantErr == "") {
+				t.Errorf("expected the decompressed file to exist only without an error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractionBudgetDefaults(t *testing.T) {
+	budget := extractionBudget{files: MaxExtractedFiles}
+	if err := budget.addFile(); err == nil {
+		t.Errorf("expected an error past %d files", MaxExtractedFiles)
+	}
+
+	budget = extractionBudget{size: MaxExtractedSize}
+	if err := budget.copy(&bytes.Buffer{}, strings.NewReader("a")); err == nil {
+		t.Errorf("expected an error past %d bytes", MaxExtractedSize)
+	}
+}
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -12,8 +15,7 @@ import (
 func FileExistsInHome(filePath string) bool {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		color.Red("Error loading home directory: %v", err)
-		os.Exit(1)
+		return false
 	}
 
 	return FileExists(filepath.Join(home, filePath))
@@ -27,31 +29,30 @@ func FileExists(filePath string) bool {
 	return false
 }
 
-func CreateDirectoryIfNotExistsInHome(directoryPath string) {
+func CreateDirectoryIfNotExistsInHome(directoryPath string) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		color.Red("Error loading home directory: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("Error loading home directory: %w", err)
 	}
 
-	CreateDirectoryIfNotExists(filepath.Join(home, directoryPath))
+	return CreateDirectoryIfNotExists(filepath.Join(home, directoryPath))
 }
 
-func CreateDirectoryIfNotExists(directoryPath string) {
+func CreateDirectoryIfNotExists(directoryPath string) error {
 	if _, err := os.Stat(directoryPath); errors.Is(err, os.ErrNotExist) {
 		err = os.MkdirAll(directoryPath, os.ModePerm)
 		if err != nil {
-			color.Red("Error creating file: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("Error creating directory: %w", err)
 		}
 	}
+
+	return nil
 }
 
 func CreateFileIfNotExistsInHome(filePath string) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		color.Red("Error loading home directory: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("Error loading home directory: %w", err)
 	}
 
 	return CreateFileIfNotExists(filepath.Join(home, filePath))
@@ -115,6 +116,27 @@ func MoveFile(file, target string) error {
 	return nil
 }
 
+// CopyFile copies the contents of file into target, replacing it
+func CopyFile(file, target string) error {
+	source, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(destination, source); err != nil {
+		_ = destination.Close()
+		return err
+	}
+
+	return destination.Close()
+}
+
 func MakeFileExecutable(path string) error {
 	data, err := ExecuteCommandAndGetOutput("chmod", []string{"+x", path}...)
 	if err != nil {
@@ -151,3 +173,25 @@ func GetPermissions(filename string) (string, error) {
 
 	return permissions, nil
 }
+
+// FileDigest returns the hex encoded SHA-256 and the size in bytes of a file
+func FileDigest(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		_ = file.Close()
+		return "", 0, err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/ricardofabila/fox/src/constants"
+	"github.com/ricardofabila/fox/src/types"
+)
+
+// directProxy in the proxy of a host connects to it without a proxy
+const directProxy = "direct"
+
+// transport is shared by every request fox makes, see UseNetwork
+var transport http.RoundTripper = http.DefaultTransport
+
+// commandEnvironment is passed to the commands fox runs, so gh uses the same proxy and certificates
+var commandEnvironment []string
+
+// ghHost is the host whose network settings gh gets, every gh command fox runs starts there
+const ghHost = "api.github.com"
+
+// Network is how fox connects to servers: the transport of its requests, how long they can wait
+// and the environment of the commands it runs
+type Network struct {
+	transport          http.RoundTripper
+	timeout            time.Duration
+	commandEnvironment []string
+}
+
+// CurrentNetwork returns the network fox uses
+func CurrentNetwork() Network {
+	return Network{transport: transport, timeout: HTTPTimeout, commandEnvironment: commandEnvironment}
+}
+
+// UseNetwork makes every request and command of fox go through the network, and returns the previous one
+func UseNetwork(network Network) Network {
+	previous := CurrentNetwork()
+	transport, HTTPTimeout, commandEnvironment = network.transport, network.timeout, network.commandEnvironment
+	return previous
+}
+
+// NetworkOf sends requests through the transport of the client, and waits for its timeout
+func NetworkOf(client *http.Client) Network {
+	network := Network{transport: client.Transport, timeout: client.Timeout}
+	if network.transport == nil {
+		network.transport = http.DefaultTransport
+	}
+
+	if network.timeout <= 0 {
+		network.timeout = constants.DefaultHTTPTimeoutSeconds * time.Second
+	}
+
+	return network
+}
+
+// hostsTransport picks the transport of the host of the request, or the default one
+type hostsTransport struct {
+	fallback *http.Transport
+	hosts    map[string]*http.Transport
+}
+
+func (t *hostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transportFor(strings.ToLower(req.URL.Hostname())).RoundTrip(req)
+}
+
+func (t *hostsTransport) transportFor(host string) *http.Transport {
+	if pattern := matchingHost(host, lo.Keys(t.hosts)); pattern != "" {
+		return t.hosts[pattern]
+	}
+
+	return t.fallback
+}
+
+// matchingHost returns the exact host, or else the longest pattern that matches it, or "" when none does
+func matchingHost(host string, patterns []string) string {
+	if lo.Contains(patterns, host) {
+		return host
+	}
+
+	// like NO_PROXY, a leading dot matches the domain and its subdomains
+	best := ""
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, ".") || len(pattern) <= len(best) {
+			continue
+		}
+
+		if host == pattern[1:] || strings.HasSuffix(host, pattern) {
+			best = pattern
+		}
+	}
+
+	return best
+}
+
+// ConfigureNetwork makes fox use the proxy, certificates, timeout and per host overrides of the user config
+func ConfigureNetwork(userConfig types.UserConfig) error {
+	network, err := NewNetwork(userConfig)
+	if err != nil {
+		return err
+	}
+
+	UseNetwork(network)
+	return nil
+}
+
+// NewNetwork builds the network of the proxy, certificates, timeout and per host overrides of the user config.
+// Nothing changes until it is given to UseNetwork.
+func NewNetwork(userConfig types.UserConfig) (Network, error) {
+	network := Network{timeout: constants.DefaultHTTPTimeoutSeconds * time.Second}
+	if userConfig.HTTPTimeoutSeconds > 0 {
+		network.timeout = time.Duration(userConfig.HTTPTimeoutSeconds) * time.Second
+	}
+
+	// the proxy of the config wins over the one of the environment, for gh too
+	proxy := http.ProxyFromEnvironment
+	if userConfig.Proxy != "" {
+		proxyURL, err := url.Parse(userConfig.Proxy)
+		if err != nil {
+			return network, fmt.Errorf("Error. The proxy in your config is not a valid URL: " + err.Error())
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	fallback, err := newTransport(userConfig.NetworkConfig, proxy, network.timeout)
+	if err != nil {
+		return network, err
+	}
+
+	hosts := map[string]*http.Transport{}
+	hostConfigs := map[string]types.NetworkConfig{}
+	for host, hostConfig := range userConfig.Hosts {
+		// what the host doesn't set comes from the global config
+		if hostConfig.CABundle == "" {
+			hostConfig.CABundle = userConfig.CABundle
+		}
+		if hostConfig.ClientCert == "" {
+			hostConfig.ClientCert, hostConfig.ClientKey = userConfig.ClientCert, userConfig.ClientKey
+		}
+		hostConfigs[strings.ToLower(host)] = hostConfig
+
+		hostProxy := proxy
+		switch hostConfig.Proxy {
+		case "":
+		case directProxy:
+			hostProxy = nil
+		default:
+			proxyURL, err := url.Parse(hostConfig.Proxy)
+			if err != nil {
+				return network, fmt.Errorf("Error. The proxy of " + host + " in your config is not a valid URL: " + err.Error())
+			}
+			hostProxy = http.ProxyURL(proxyURL)
+		}
+
+		hosts[strings.ToLower(host)], err = newTransport(hostConfig, hostProxy, network.timeout)
+		if err != nil {
+			return network, fmt.Errorf("Error in the config of " + host + ": " + err.Error())
+		}
+	}
+
+	network.transport = &hostsTransport{fallback: fallback, hosts: hosts}
+
+	ghConfig := userConfig.NetworkConfig
+	if pattern := matchingHost(ghHost, lo.Keys(hostConfigs)); pattern != "" {
+		ghConfig = hostConfigs[pattern]
+		if ghConfig.Proxy == "" {
+			ghConfig.Proxy = userConfig.Proxy
+		}
+	}
+	network.commandEnvironment = newCommandEnvironment(ghConfig)
+
+	return network, nil
+}
+
+// newCommandEnvironment passes the proxy and certificates of the network config to gh.
+// gh can't be given a client certificate, it reads extra certificates from SSL_CERT_DIR only on Linux,
+// and it uses the settings of ghHost for the hosts release downloads redirect to too.
+// Downloads of release assets go through the transport of fox instead when there is a GitHub token.
+func newCommandEnvironment(config types.NetworkConfig) []string {
+	var environment []string
+
+	// the proxy of the config wins over the one of the environment
+	switch config.Proxy {
+	case "":
+	case directProxy:
+		for _, name := range []string{"NO_PROXY", "no_proxy"} {
+			environment = append(environment, name+"=*")
+		}
+	default:
+		for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy"} {
+			environment = append(environment, name+"="+config.Proxy)
+		}
+	}
+
+	// Go programs like gh read extra certificates from SSL_CERT_DIR on Linux, with the system ones
+	if config.CABundle != "" && runtime.GOOS == "linux" {
+		directories := []string{filepath.Dir(expandHome(config.CABundle)), "/etc/ssl/certs", "/etc/pki/tls/certs"}
+		if existing := os.Getenv("SSL_CERT_DIR"); existing != "" {
+			directories = append(directories, existing)
+		}
+		environment = append(environment, "SSL_CERT_DIR="+strings.Join(directories, ":"))
+	}
+
+	return environment
+}
+
+func newTransport(config types.NetworkConfig, proxy func(*http.Request) (*url.URL, error), timeout time.Duration) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(expandHome(config.CABundle))
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the caBundle: " + err.Error())
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Error. The caBundle " + config.CABundle + " has no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCert != "" {
+		// the key can be in the same file as the certificate
+		key := config.ClientKey
+		if key == "" {
+			key = config.ClientCert
+		}
+
+		certificate, err := tls.LoadX509KeyPair(expandHome(config.ClientCert), expandHome(key))
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the client certificate: " + err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           (&net.Dialer{Timeout: timeout, KeepAlive: timeout}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+	}, nil
+}
+
+// expandHome expands a leading ~/ to the home directory of the user
+func expandHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ricardofabila/fox/src/types"
+)
+
+func TestHostsTransport(t *testing.T) {
+	fallback := &http.Transport{}
+	hosts := map[string]*http.Transport{
+		"github.com":                     {},
+		".github.com":                    {},
+		".githubusercontent.com":         {},
+		".objects.githubusercontent.com": {},
+	}
+	transport := &hostsTransport{fallback: fallback, hosts: hosts}
+
+	tests := []struct {
+		host string
+		want *http.Transport
+	}{
+		{host: "github.com", want: hosts["github.com"]},
+		{host: "api.github.com", want: hosts[".github.com"]},
+		{host: "githubusercontent.com", want: hosts[".githubusercontent.com"]},
+		{host: "raw.githubusercontent.com", want: hosts[".githubusercontent.com"]},
+		{host: "objects.githubusercontent.com", want: hosts[".objects.githubusercontent.com"]},
+		{host: "a.objects.githubusercontent.com", want: hosts[".objects.githubusercontent.com"]},
+		{host: "notgithub.com", want: fallback},
+	}
+
+	for _, tt := range tests {
+		// the map is ranged in a different order every time
+		for i := 0; i < 20; i++ {
+			if got := transport.transportFor(tt.host); got != tt.want {
+				t.Fatalf("%s: got the transport of the wrong host", tt.host)
+			}
+		}
+	}
+}
+
+func TestCommandEnvironment(t *testing.T) {
+	proxied := func(proxy string) []string {
+		return []string{"HTTPS_PROXY=" + proxy, "HTTP_PROXY=" + proxy, "https_proxy=" + proxy, "http_proxy=" + proxy}
+	}
+
+	tests := []struct {
+		name       string
+		userConfig types.UserConfig
+		want       []string
+	}{
+		{name: "nothing set", want: nil},
+		{
+			name:       "global proxy",
+			userConfig: types.UserConfig{NetworkConfig: types.NetworkConfig{Proxy: "http://proxy:3128"}},
+			want:       proxied("http://proxy:3128"),
+		},
+		{
+			name: "proxy of the GitHub API",
+			userConfig: types.UserConfig{
+				NetworkConfig: types.NetworkConfig{Proxy: "http://proxy:3128"},
+				Hosts:         map[string]types.NetworkConfig{".GitHub.com": {Proxy: "http://github-proxy:3128"}},
+			},
+			want: proxied("http://github-proxy:3128"),
+		},
+		{
+			name: "the GitHub API without a proxy",
+			userConfig: types.UserConfig{
+				NetworkConfig: types.NetworkConfig{Proxy: "http://proxy:3128"},
+				Hosts:         map[string]types.NetworkConfig{"api.github.com": {Proxy: directProxy}},
+			},
+			want: []string{"NO_PROXY=*", "no_proxy=*"},
+		},
+		{
+			name: "host that doesn't set a proxy",
+			userConfig: types.UserConfig{
+				NetworkConfig: types.NetworkConfig{Proxy: "http://proxy:3128"},
+				Hosts:         map[string]types.NetworkConfig{".github.com": {}},
+			},
+			want: proxied("http://proxy:3128"),
+		},
+		{
+			name: "other host",
+			userConfig: types.UserConfig{
+				Hosts: map[string]types.NetworkConfig{"artifacts.internal": {Proxy: "http://proxy:3128"}},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		network, err := NewNetwork(tt.userConfig)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		if !reflect.DeepEqual(network.commandEnvironment, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, network.commandEnvironment)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	markdownHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownNumbered   = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	markdownRule       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))+\s*$`)
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownCode       = regexp.MustCompile("`([^`]+)`")
+	markdownBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownItalic     = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*|(^|[^_\w])_([^_\s][^_]*)_`)
+	markdownHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	markdownHTMLBreaks = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// RenderMarkdown renders release notes, and other markdown, for the terminal.
+// It covers what GitHub release notes use: headings, lists, code, quotes, links and emphasis.
+func RenderMarkdown(markdown string, width int) string {
+	heading := color.New(color.Bold, color.FgHiMagenta).SprintFunc()
+	subheading := color.New(color.Bold, color.FgBlue).SprintFunc()
+	code := color.New(color.FgYellow).SprintFunc()
+	quote := color.New(color.Faint).SprintFunc()
+
+	var out []string
+	inCodeBlock := false
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+
+		if inCodeBlock {
+			out = append(out, "    "+code(line))
+			continue
+		}
+
+		line = markdownHTMLBreaks.ReplaceAllString(line, "")
+		line = markdownHTMLTag.ReplaceAllString(line, "")
+
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			text := renderInlineMarkdown(match[2])
+			if len(match[1]) <= 2 {
+				out = append(out, "", heading(strings.ToUpper(text)))
+			} else {
+				out = append(out, "", subheading(text))
+			}
+			continue
+		}
+
+		if markdownRule.MatchString(line) {
+			out = append(out, quote(strings.Repeat("─", ruleWidth(width))))
+			continue
+		}
+
+		if match := markdownBullet.FindStringSubmatch(line); match != nil {
+			out = append(out, wrapMarkdown(match[1]+"  • ", renderInlineMarkdown(match[2]), width)...)
+			continue
+		}
+
+		if match := markdownNumbered.FindStringSubmatch(line); match != nil {
+			out = append(out, wrapMarkdown(match[1]+"  "+match[2]+". ", renderInlineMarkdown(match[3]), width)...)
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ">"))
+			for _, l := range wrapMarkdown("  │ ", renderInlineMarkdown(text), width) {
+				out = append(out, quote(l))
+			}
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			// one blank line between paragraphs is enough
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			continue
+		}
+
+		out = append(out, wrapMarkdown("", renderInlineMarkdown(strings.TrimSpace(line)), width)...)
+	}
+
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+func renderInlineMarkdown(text string) string {
+	bold := color.New(color.Bold).SprintFunc()
+	italic := color.New(color.Italic).SprintFunc()
+	code := color.New(color.FgYellow).SprintFunc()
+	link := color.New(color.FgCyan, color.Underline).SprintFunc()
+
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownCode.ReplaceAllStringFunc(text, func(s string) string {
+		return code(markdownCode.FindStringSubmatch(s)[1])
+	})
+	text = markdownLink.ReplaceAllStringFunc(text, func(s string) string {
+		match := markdownLink.FindStringSubmatch(s)
+		if match[1] == match[2] {
+			return link(match[2])
+		}
+
+		return match[1] + " (" + link(match[2]) + ")"
+	})
+	text = markdownBold.ReplaceAllStringFunc(text, func(s string) string {
+		match := markdownBold.FindStringSubmatch(s)
+		return bold(match[1] + match[2])
+	})
+	text = markdownItalic.ReplaceAllStringFunc(text, func(s string) string {
+		match := markdownItalic.FindStringSubmatch(s)
+		return match[1] + match[3] + italic(match[2]+match[4])
+	})
+
+	return text
+}
+
+// wrapMarkdown wraps the text to the width, indenting the lines after the first one as much as the prefix
+func wrapMarkdown(prefix, text string, width int) []string {
+	if width <= 0 {
+		return []string{prefix + text}
+	}
+
+	indent := strings.Repeat(" ", len([]rune(prefix)))
+	var lines []string
+	line := prefix
+	visible := len([]rune(prefix))
+	for _, word := range strings.Fields(text) {
+		length := len([]rune(ansiEscape.ReplaceAllString(word, "")))
+		if visible+length > width && strings.TrimSpace(line) != strings.TrimSpace(prefix) {
+			lines = append(lines, strings.TrimRight(line, " "))
+			line = indent
+			visible = len(indent)
+		}
+
+		line += word + " "
+		visible += length + 1
+	}
+
+	return append(lines, strings.TrimRight(line, " "))
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// StripANSI removes the colors from text
+func StripANSI(text string) string {
+	return ansiEscape.ReplaceAllString(text, "")
+}
+
+// ruleWidth is the width of a horizontal rule, 40 characters at most
+func ruleWidth(width int) int {
+	if width > 0 && width < 40 {
+		return width
+	}
+
+	return 40
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ricardofabila/fox/src/constants"
+)
+
+var debMagicNumber = []byte("!<arch>\n")
+var rpmMagicNumber = []byte{0xed, 0xab, 0xee, 0xdb}
+var rpmHeaderMagicNumber = []byte{0x8e, 0xad, 0xe8, 0x01}
+
+const (
+	arHeaderSize   = 60
+	rpmLeadSize    = 96
+	cpioHeaderSize = 110
+	cpioTrailer    = "TRAILER!!!"
+)
+
+func FileHasLinuxPackageExtension(filename string) bool {
+	for _, extension := range constants.LinuxPackageExtensions {
+		if strings.HasSuffix(strings.ToLower(filename), extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FileHasAppImageExtension(filename string) bool {
+	for _, extension := range constants.AppImageExtensions {
+		if strings.HasSuffix(filename, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExtractDeb extracts the files of a Debian package, found in its data.tar.* member, into dst
+func ExtractDeb(src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	magic := make([]byte, len(debMagicNumber))
+	if _, err = io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, debMagicNumber) {
+		return fmt.Errorf("the file is not a valid Debian package: " + src)
+	}
+
+	header := make([]byte, arHeaderSize)
+	for {
+		if _, err = io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("the Debian package has no data.tar member: " + src)
+		}
+
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return fmt.Errorf("the file is not a valid Debian package: " + src)
+		}
+
+		if strings.HasPrefix(name, "data.tar") {
+			return extractTARStream(io.LimitReader(r, size), dst, &extractionBudget{})
+		}
+
+		// members are aligned to 2 bytes
+		if _, err = io.CopyN(io.Discard, r, size+size%2); err != nil {
+			return err
+		}
+	}
+}
+
+// ExtractRPM extracts the files of an RPM package, found in its cpio payload, into dst
+func ExtractRPM(src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	lead := make([]byte, rpmLeadSize)
+	if _, err = io.ReadFull(r, lead); err != nil || !bytes.HasPrefix(lead, rpmMagicNumber) {
+		return fmt.Errorf("the file is not a valid RPM package: " + src)
+	}
+
+	// the signature header is padded to 8 bytes, the main header isn't
+	for _, padded := range []bool{true, false} {
+		if err = skipRPMHeader(r, padded); err != nil {
+			return fmt.Errorf("the file is not a valid RPM package: %s: %s", src, err)
+		}
+	}
+
+	payload, closeDecompressor, err := Decompress(r)
+	if err != nil {
+		return err
+	}
+	defer closeDecompressor()
+
+	return extractCPIO(payload, dst)
+}
+
+func skipRPMHeader(r io.Reader, padded bool) error {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(header[0:4], rpmHeaderMagicNumber) {
+		return fmt.Errorf("bad header magic number")
+	}
+
+	entries := int64(binary.BigEndian.Uint32(header[8:12]))
+	dataSize := int64(binary.BigEndian.Uint32(header[12:16]))
+	size := entries*16 + dataSize
+	if padded {
+		size += (8 - (16+size)%8) % 8
+	}
+
+	_, err := io.CopyN(io.Discard, r, size)
+	return err
+}
+
+// extractCPIO extracts a cpio archive in the "new ASCII" format that RPM payloads use
+func extractCPIO(r io.Reader, dst string) error {
+	var budget extractionBudget
+	header := make([]byte, cpioHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("invalid cpio payload: %s", err)
+		}
+
+		magic := string(header[0:6])
+		if magic != "070701" && magic != "070702" {
+			return fmt.Errorf("invalid cpio payload: unsupported format " + magic)
+		}
+
+		field := func(i int) (int64, error) {
+			return strconv.ParseInt(string(header[6+i*8:14+i*8]), 16, 64)
+		}
+
+		mode, err := field(1)
+		if err != nil {
+			return err
+		}
+
+		fileSize, err := field(6)
+		if err != nil {
+			return err
+		}
+
+		nameSize, err := field(11)
+		if err != nil || nameSize <= 0 || nameSize > 4096 {
+			return fmt.Errorf("invalid cpio payload: bad name size")
+		}
+
+		// the header and the name are padded to 4 bytes, so is the data
+		name := make([]byte, nameSize+(4-(cpioHeaderSize+nameSize)%4)%4)
+		if _, err = io.ReadFull(r, name); err != nil {
+			return err
+		}
+
+		entryName := string(bytes.TrimRight(name[:nameSize], "\x00"))
+		if entryName == cpioTrailer {
+			return nil
+		}
+
+		data := io.LimitReader(r, fileSize)
+		if err = extractCPIOEntry(dst, entryName, os.FileMode(mode), data, &budget); err != nil {
+			return err
+		}
+
+		// whatever the entry didn't use plus the padding
+		if _, err = io.Copy(io.Discard, data); err != nil {
+			return err
+		}
+
+		if _, err = io.CopyN(io.Discard, r, (4-fileSize%4)%4); err != nil {
+			return err
+		}
+	}
+}
+
+func extractCPIOEntry(dst, name string, mode os.FileMode, data io.Reader, budget *extractionBudget) error {
+	if err := budget.addFile(); err != nil {
+		return err
+	}
+
+	// entries are named like ./usr/bin/tool
+	path, err := safeJoin(dst, strings.TrimPrefix(name, "./"))
+	if err != nil {
+		return err
+	}
+
+	switch uint32(mode) & 0170000 {
+	case 0040000:
+		return safeDirectory(dst, path)
+	case 0100000:
+		return createExtractedFile(dst, path, mode, data, budget)
+	case 0120000:
+		target, err := io.ReadAll(io.LimitReader(data, 4096))
+		if err != nil {
+			return err
+		}
+
+		return safeSymlink(dst, path, string(target))
+	}
+
+	// devices, fifos and the like have no business in a package fox installs
+	return nil
+}
@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cpioArchive builds a payload in the "new ASCII" cpio format of RPM packages
+func cpioArchive(entries []archiveEntry) []byte {
+	var buf bytes.Buffer
+	pad := func(n int) {
+		buf.Write(make([]byte, (4-n%4)%4))
+	}
+
+	write := func(name string, mode int64, body string) {
+		fields := []int64{0, mode, 0, 0, 1, 0, int64(len(body)), 0, 0, 0, 0, int64(len(name) + 1), 0}
+		buf.WriteString("070701")
+		for _, f := range fields {
+			buf.WriteString(fmt.Sprintf("%08x", f))
+		}
+		buf.WriteString(name + "\x00")
+		pad(cpioHeaderSize + len(name) + 1)
+		buf.WriteString(body)
+		pad(len(body))
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.dir:
+			write(e.name, 0040755, "")
+		case e.symlink != "":
+			write(e.name, 0120777, e.symlink)
+		default:
+			write(e.name, 0100755, e.body)
+		}
+	}
+	write(cpioTrailer, 0, "")
+
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// debPackage builds a Debian package whose data.tar.gz has the entries
+func debPackage(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write(debMagicNumber)
+	member := func(name string, data []byte) {
+		buf.WriteString(fmt.Sprintf("%-16s%-12s%-6s%-6s%-8s%-10d`\n", name, "0", "0", "0", "100644", len(data)))
+		buf.Write(data)
+		if len(data)%2 == 1 {
+			buf.WriteString("\n")
+		}
+	}
+
+	member("debian-binary", []byte("2.0\n"))
+	member("control.tar.gz", gzipped(t, tarArchive(t, []archiveEntry{{name: "./control", body: "Package: tool\n"}}).Bytes()))
+	member("data.tar.gz", gzipped(t, tarArchive(t, entries).Bytes()))
+
+	return buf.Bytes()
+}
+
+// rpmPackage builds an RPM package with empty headers and a gzipped cpio payload of the entries
+func rpmPackage(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmMagicNumber)
+	buf.Write(lead)
+
+	// the signature and the main header, without entries
+	for i := 0; i < 2; i++ {
+		buf.Write(rpmHeaderMagicNumber)
+		buf.Write(make([]byte, 12))
+	}
+
+	buf.Write(gzipped(t, cpioArchive(entries)))
+
+	return buf.Bytes()
+}
+
+func writePackage(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func assertExtractedFiles(t *testing.T, dst string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != content {
+			t.Errorf("%s: expected %q, got %q", name, content, data)
+		}
+	}
+}
+
+func TestExtractDeb(t *testing.T) {
+	valid := debPackage(t, []archiveEntry{
+		{name: "./usr/", dir: true},
+		{name: "./usr/bin/tool", body: "binary"},
+		{name: "./usr/bin/alias", symlink: "tool"},
+	})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+		files   map[string]string
+	}{
+		{
+			name:  "valid package",
+			data:  valid,
+			files: map[string]string{"usr/bin/tool": "binary", "usr/bin/alias": "binary"},
+		},
+		{
+			name:    "not a package",
+			data:    []byte("#!/bin/sh\necho hello\n"),
+			wantErr: "is not a valid Debian package",
+		},
+		{
+			name:    "truncated member header",
+			data:    valid[:len(debMagicNumber)+arHeaderSize/2],
+			wantErr: "has no data.tar member",
+		},
+		{
+			name:    "bad member size",
+			data:    append(append([]byte{}, debMagicNumber...), []byte(fmt.Sprintf("%-16s%-32s%-10s`\n", "debian-binary", "0", "big"))...),
+			wantErr: "is not a valid Debian package",
+		},
+		{
+			name:    "parent directory",
+			data:    debPackage(t, []archiveEntry{{name: "../evil", body: "x"}}),
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "symlink outside",
+			data:    debPackage(t, []archiveEntry{{name: "./usr/bin/link", symlink: "../../../evil"}}),
+			wantErr: "points outside the archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, dst := destination(t)
+			err := ExtractDeb(writePackage(t, "tool.deb", tt.data), dst)
+			assertExtractionError(t, err, tt.wantErr)
+			assertNothingEscaped(t, root)
+			assertExtractedFiles(t, dst, tt.files)
+		})
+	}
+}
+
+func TestExtractRPM(t *testing.T) {
+	valid := rpmPackage(t, []archiveEntry{
+		{name: "./usr", dir: true},
+		{name: "./usr/bin/tool", body: "binary"},
+		{name: "./usr/bin/alias", symlink: "tool"},
+	})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+		files   map[string]string
+	}{
+		{
+			name:  "valid package",
+			data:  valid,
+			files: map[string]string{"usr/bin/tool": "binary", "usr/bin/alias": "binary"},
+		},
+		{
+			name:    "not a package",
+			data:    []byte("#!/bin/sh\necho hello\n"),
+			wantErr: "is not a valid RPM package",
+		},
+		{
+			name:    "truncated header",
+			data:    valid[:rpmLeadSize+8],
+			wantErr: "is not a valid RPM package",
+		},
+		{
+			name:    "bad header magic number",
+			data:    append(append([]byte{}, valid[:rpmLeadSize]...), make([]byte, 32)...),
+			wantErr: "bad header magic number",
+		},
+		{
+			name:    "parent directory",
+			data:    rpmPackage(t, []archiveEntry{{name: "../evil", body: "x"}}),
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "symlink outside",
+			data:    rpmPackage(t, []archiveEntry{{name: "./usr/bin/link", symlink: "../../../evil"}}),
+			wantErr: "points outside the archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, dst := destination(t)
+			err := ExtractRPM(writePackage(t, "tool.rpm", tt.data), dst)
+			assertExtractionError(t, err, tt.wantErr)
+			assertNothingEscaped(t, root)
+			assertExtractedFiles(t, dst, tt.files)
+		})
+	}
+}
+
+func TestExtractCPIO(t *testing.T) {
+	valid := cpioArchive([]archiveEntry{
+		{name: "./usr/bin/tool", body: "binary"},
+		{name: "./usr/share/doc/tool/README", body: "odd length"},
+	})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+		files   map[string]string
+	}{
+		{
+			name:  "valid payload",
+			data:  valid,
+			files: map[string]string{"usr/bin/tool": "binary", "usr/share/doc/tool/README": "odd length"},
+		},
+		{
+			name:    "truncated header",
+			data:    valid[:cpioHeaderSize/2],
+			wantErr: "invalid cpio payload",
+		},
+		{
+			name:    "missing trailer",
+			data:    valid[:len(valid)-cpioHeaderSize-len(cpioTrailer)-2],
+			wantErr: "invalid cpio payload",
+		},
+		{
+			name:    "unsupported format",
+			data:    append([]byte("070707"), valid[6:]...),
+			wantErr: "unsupported format 070707",
+		},
+		{
+			name:    "parent directory",
+			data:    cpioArchive([]archiveEntry{{name: "./usr/../../evil", body: "x"}}),
+			wantErr: "escapes the destination",
+		},
+		{
+			name:    "absolute path",
+			data:    cpioArchive([]archiveEntry{{name: "/tmp/evil", body: "x"}}),
+			wantErr: "it is absolute",
+		},
+		{
+			name: "symlink through a symlinked directory",
+			data: cpioArchive([]archiveEntry{
+				{name: "./bin", symlink: "."},
+				{name: "./bin/link", symlink: "../evil"},
+			}),
+			wantErr: "points outside the archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, dst := destination(t)
+			err := extractCPIO(bytes.NewReader(tt.data), dst)
+			assertExtractionError(t, err, tt.wantErr)
+			assertNothingEscaped(t, root)
+			assertExtractedFiles(t, dst, tt.files)
+		})
+	}
+}
@@ -2,7 +2,7 @@ package utils
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -34,24 +34,28 @@ func TarWithoutExtension(fileName string) string {
 }
 
 // ExtractTAR takes a destination path and a reader; a tar reader loops over the tarfile
-// creating the file structure at 'dst' along the way, and writing any files
+// creating the file structure at 'dst' along the way, and writing any files.
+// The tar can be uncompressed or compressed with gzip, bzip2, xz or zstd.
+// Entries that would end up outside 'dst', including through links, are rejected.
 func ExtractTAR(src, dst string) error {
-	r, e := os.Open(src)
-	if e != nil {
-		err := r.Close()
-		if err != nil {
-			return err
-		}
-
-		return e
+	r, err := os.Open(src)
+	if err != nil {
+		return err
 	}
+	defer r.Close()
+
+	return extractTARStream(r, dst, &extractionBudget{})
+}
 
-	gzr, gErr := gzip.NewReader(r)
-	if gErr != nil {
-		return gErr
+// extractTARStream extracts a tar, compressed or not, that is read from r
+func extractTARStream(r io.Reader, dst string, budget *extractionBudget) error {
+	decompressed, closeDecompressor, err := Decompress(r)
+	if err != nil {
+		return err
 	}
+	defer closeDecompressor()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(decompressed)
 	for {
 		header, err := tr.Next()
 
@@ -59,15 +63,6 @@ func ExtractTAR(src, dst string) error {
 
 		// if no more files are found return
 		case err == io.EOF:
-			err = gzr.Close()
-			if err != nil {
-				return err
-			}
-			err = r.Close()
-			if err != nil {
-				return err
-			}
-
 			return nil
 
 		// return any other error
@@ -79,54 +74,84 @@ func ExtractTAR(src, dst string) error {
 			continue
 		}
 
-		// the current location where the dir/file should be created
-		current := filepath.Join(dst, header.Name)
-		dir, _ := filepath.Split(current)
-		CreateDirectoryIfNotExists(dir)
+		if err = budget.addFile(); err != nil {
+			return err
+		}
 
-		// the following switch could also be done using fi.Mode(), not sure if is there
-		// a benefit of using one vs. the other.
-		// fi := header.FileInfo()
+		// the current location where the dir/file should be created
+		current, err := safeJoin(dst, header.Name)
+		if err != nil {
+			return err
+		}
 
 		// check the file type
 		switch header.Typeflag {
 		// if it's a dir, and it doesn't, exist create it
 		case tar.TypeDir:
-			if _, err = os.Stat(current); err != nil {
-				if err = os.MkdirAll(current, 0755); err != nil {
-					return err
-				}
+			if err = safeDirectory(dst, current); err != nil {
+				return err
 			}
 
 		// if it's a file create it
-		case tar.TypeReg:
-			f, fErr := os.OpenFile(current, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if fErr != nil {
-				// for some reason directories are sometimes mistaken as regular files
-				if strings.Contains(fErr.Error(), "is a directory") {
-					if _, err = os.Stat(current); err != nil {
-						if err = os.MkdirAll(current, 0755); err != nil {
-							return err
-						}
-					}
-
-					continue
-				}
-
-				return fErr
+		case tar.TypeReg, tar.TypeRegA:
+			if err = createExtractedFile(dst, current, os.FileMode(header.Mode), tr, budget); err != nil {
+				return err
 			}
 
-			// copy over contents
-			if _, err = io.Copy(f, tr); err != nil {
+		// eg: bin/tool -> ../libexec/tool
+		case tar.TypeSymlink:
+			if err = safeSymlink(dst, current, header.Linkname); err != nil {
 				return err
 			}
 
-			// manually close here after each file operation; defering would cause each file close
-			// to wait until all operations have completed.
-			err = f.Close()
+		// hard links point to an entry earlier in the same archive, copy it
+		case tar.TypeLink:
+			target, err := safeJoin(dst, header.Linkname)
 			if err != nil {
 				return err
 			}
+
+			if err = copyExtractedFile(dst, target, current, budget); err != nil {
+				return err
+			}
+
+		// devices, fifos and the like have no business in a release archive
+		default:
+			continue
 		}
 	}
 }
+
+// copyExtractedFile copies an already extracted file, the target of a hard link
+func copyExtractedFile(dst, target, path string, budget *extractionBudget) error {
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return fmt.Errorf("invalid hard link in archive, its target was not extracted: " + path)
+	}
+
+	resolvedDst, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	if !isInside(resolvedDst, resolved) {
+		return fmt.Errorf("invalid hard link in archive, it points outside the archive: " + path)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid hard link in archive, its target is not a file: " + path)
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return createExtractedFile(dst, path, info.Mode(), f, budget)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/gob"
 	"encoding/json"
@@ -16,31 +17,9 @@ import (
 	"github.com/goccy/go-yaml/lexer"
 	"github.com/goccy/go-yaml/printer"
 	"github.com/mattn/go-colorable"
-	"github.com/spf13/cobra"
 	"golang.org/x/sys/execabs"
 )
 
-// CheckErr prints the msg with the prefix 'Error:' and exits with error code 1. If the msg is nil, it does nothing.
-func CheckErr(e error, cmd *cobra.Command) {
-	if e != nil {
-		fmt.Println()
-		fmt.Println()
-		_, err := color.New(color.FgRed).Fprintln(os.Stderr, " (╯°□°)╯︵ ɹoɹɹƎ \n\n\n "+e.Error())
-		if err != nil {
-			color.Red(err.Error())
-		}
-
-		fmt.Println()
-		fmt.Println()
-
-		if cmd != nil {
-			_ = cmd.Help()
-		}
-
-		os.Exit(1)
-	}
-}
-
 func PrintAndReturnError(text string) error {
 	color.Red(text)
 	return fmt.Errorf(text)
@@ -288,47 +267,49 @@ func PrintJSONString(withColor bool, body string) error {
 	return PrintJSON(withColor, []byte(body))
 }
 
-func ParseJSON(bytes []byte) interface{} {
+func ParseJSON(bytes []byte) (interface{}, error) {
 	var obj interface{}
 	err := json.Unmarshal(bytes, &obj)
 	if err != nil {
-		color.Red("Error the parsing JSON: %s \n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error parsing the JSON: %w", err)
 	}
-	return obj
+	return obj, nil
 }
 
-func ParseJSONIntoMap(bytes []byte) map[string]interface{} {
+func ParseJSONIntoMap(bytes []byte) (map[string]interface{}, error) {
 	var obj map[string]interface{}
 	err := json.Unmarshal(bytes, &obj)
 	if err != nil {
-		color.Red("Error the parsing JSON: %s \n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error parsing the JSON: %w", err)
 	}
-	return obj
+	return obj, nil
 }
 
-func ParseInterfaceIntoJSON(yourThing interface{}) []byte {
+func ParseInterfaceIntoJSON(yourThing interface{}) ([]byte, error) {
 	obj, err := json.Marshal(yourThing)
 	if err != nil {
-		color.Red("Error the parsing JSON: %s \n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error encoding the JSON: %w", err)
 	}
-	return obj
+	return obj, nil
 }
 
-func ParseJSONIntoArray(bytes []byte) []interface{} {
+func ParseJSONIntoArray(bytes []byte) ([]interface{}, error) {
 	var obj []interface{}
 	err := json.Unmarshal(bytes, &obj)
 	if err != nil {
-		color.Red("Error the parsing JSON: %s \n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error parsing the JSON: %w", err)
 	}
-	return obj
+	return obj, nil
 }
 
 func ExecuteCommandAndGetOutput(command string, flags ...string) (string, error) {
-	cmd := exec.Command(command, flags...)
+	return ExecuteCommandAndGetOutputContext(context.Background(), command, flags...)
+}
+
+// ExecuteCommandAndGetOutputContext is ExecuteCommandAndGetOutput, killing the command when the context is done
+func ExecuteCommandAndGetOutputContext(ctx context.Context, command string, flags ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, command, flags...)
+	cmd.Env = append(os.Environ(), commandEnvironment...)
 	var outb, errb bytes.Buffer
 	cmd.Stdout = &outb
 	cmd.Stderr = &errb
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/ricardofabila/fox/src/constants"
@@ -34,60 +33,69 @@ func ZIPWithoutExtension(fileName string) string {
 }
 
 // ExtractZIP takes a destination path and a reader; a tar reader loops over the zip file
-// creating the file structure at 'dst' along the way, and writing any files
+// creating the file structure at 'dst' along the way, and writing any files.
+// Entries that would end up outside 'dst', including through links, are rejected.
 func ExtractZIP(src, dst string) error {
+	return extractZIP(src, dst, &extractionBudget{})
+}
+
+func extractZIP(src, dst string, budget *extractionBudget) error {
 	archive, err := zip.OpenReader(src)
 	if err != nil {
 		return err
 	}
+	defer archive.Close()
+
+	if _, maxFiles := budget.limits(); len(archive.File) > maxFiles {
+		return fmt.Errorf("the archive has more than %d files, refusing to extract it", maxFiles)
+	}
 
 	for _, f := range archive.File {
-		filePath := filepath.Join(dst, f.Name)
+		if err = budget.addFile(); err != nil {
+			return err
+		}
 
-		if !strings.HasPrefix(filePath, filepath.Clean(dst)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid file path")
+		filePath, err := safeJoin(dst, f.Name)
+		if err != nil {
+			return err
 		}
+
 		if f.FileInfo().IsDir() {
-			err = os.MkdirAll(filePath, os.ModePerm)
+			err = safeDirectory(dst, filePath)
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		if err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		if err = extractZIPEntry(dst, filePath, f, budget); err != nil {
 			return err
 		}
+	}
 
-		dstFile, errD := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if errD != nil {
-			return errD
-		}
-
-		fileInArchive, errF := f.Open()
-		if errF != nil {
-			return errF
-		}
+	return nil
+}
 
-		if _, err = io.Copy(dstFile, fileInArchive); err != nil {
-			return err
-		}
+func extractZIPEntry(dst, filePath string, f *zip.File, budget *extractionBudget) error {
+	fileInArchive, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer fileInArchive.Close()
 
-		err = dstFile.Close()
+	// zip files made on unix can store symlinks, the target is the content of the entry
+	if f.Mode()&os.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(fileInArchive, 4096))
 		if err != nil {
 			return err
 		}
 
-		err = fileInArchive.Close()
-		if err != nil {
-			return err
-		}
+		return safeSymlink(dst, filePath, string(target))
 	}
 
-	err = archive.Close()
-	if err != nil {
-		return err
+	if !f.Mode().IsRegular() {
+		return nil
 	}
 
-	return nil
+	return createExtractedFile(dst, filePath, f.Mode(), fileInArchive, budget)
 }